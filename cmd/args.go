@@ -21,7 +21,7 @@ func (a *Arguments) Get(name string) (string, bool) {
 	}
 
 	for i, arg := range a.defs {
-		if strings.Contains(arg, name) {
+		if arg == argPrefix+name || strings.HasPrefix(arg, argPrefix+name+argValueSeparator) {
 			return a.value(i), true
 		}
 	}
@@ -31,7 +31,20 @@ func (a *Arguments) Get(name string) (string, bool) {
 func (a *Arguments) value(idx int) string {
 	val := a.defs[idx]
 	if strings.Contains(val, argValueSeparator) {
-		val = strings.Split(val, argValueSeparator)[1]
+		val = strings.SplitN(val, argValueSeparator, 2)[1]
 	}
 	return val
 }
+
+// With returns a copy of the arguments in which the named argument has the given value, e.g. to run the same command with different values.
+func (a Arguments) With(name, value string) Arguments {
+	name = strings.TrimPrefix(name, argPrefix)
+	res := Arguments{}
+	for _, arg := range a.defs {
+		if arg != argPrefix+name && !strings.HasPrefix(arg, argPrefix+name+argValueSeparator) {
+			res.Add(arg)
+		}
+	}
+	res.Add(argPrefix + name + argValueSeparator + value)
+	return res
+}
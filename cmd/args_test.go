@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentsGet(t *testing.T) {
+	args := Arguments{}
+	args.Add("--overwrite-csv-file")
+	args.Add("--csv-file=simulation.csv")
+	args.Add("--spread-percent=0.02")
+
+	val, ok := args.Get("--csv-file")
+	assert.True(t, ok)
+	assert.Equal(t, "simulation.csv", val)
+
+	val, ok = args.Get("overwrite-csv-file")
+	assert.True(t, ok)
+	assert.Equal(t, "--overwrite-csv-file", val)
+
+	val, ok = args.Get("--spread-percent")
+	assert.True(t, ok)
+	assert.Equal(t, "0.02", val)
+
+	_, ok = args.Get("--percent")
+	assert.False(t, ok)
+}
+
+func TestArgumentsWith(t *testing.T) {
+	args := Arguments{}
+	args.Add("--spread-percent=0.02")
+	args.Add("--seed=1")
+
+	changed := args.With("--spread-percent", "0.1")
+	added := args.With("object-count", "500")
+
+	val, _ := changed.Get("--spread-percent")
+	assert.Equal(t, "0.1", val)
+	val, _ = args.Get("--spread-percent")
+	assert.Equal(t, "0.02", val)
+	val, _ = added.Get("--object-count")
+	assert.Equal(t, "500", val)
+	val, _ = added.Get("--seed")
+	assert.Equal(t, "1", val)
+}
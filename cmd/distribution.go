@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const distributionFieldSeparator = ":"
+
+// AsDistribution parses a distribution of durations in one of the formats:
+// const:<time>, uniform:<min-time>:<max-time> or exp:<mean-time>. For example: "uniform:50ms:2s".
+func AsDistribution(userValue string) (model.Distribution, error) {
+	fields := strings.Split(strings.TrimSpace(userValue), distributionFieldSeparator)
+
+	values := make([]float64, 0, len(fields)-1)
+	for _, field := range fields[1:] {
+		millis, err := AsMillis(field)
+		if err != nil || millis < 0 {
+			return nil, fmt.Errorf("invalid duration in distribution: %s", userValue)
+		}
+		values = append(values, float64(millis))
+	}
+
+	switch {
+	case fields[0] == "const" && len(values) == 1:
+		return model.ConstantDistribution{Value: values[0]}, nil
+	case fields[0] == "uniform" && len(values) == 2 && values[0] <= values[1]:
+		return model.UniformDistribution{Min: values[0], Max: values[1]}, nil
+	case fields[0] == "exp" && len(values) == 1:
+		return model.ExponentialDistribution{Mean: values[0]}, nil
+	}
+	return nil, fmt.Errorf("invalid distribution: %s", userValue)
+}
@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// WithSuffix inserts the suffix into the file name, before the extension.
+func WithSuffix(fileName, suffix string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + suffix + ext
+}
@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSuffix(t *testing.T) {
+	assert.Equal(t, "out-heatmap.png", WithSuffix("out.png", "-heatmap"))
+	assert.Equal(t, "dir.v2/out-run1", WithSuffix("dir.v2/out", "-run1"))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// heatmapPhaseBuckets is the number of rows of the phase heatmap, two pixels each.
+const heatmapPhaseBuckets = 200
+
+// drawHeatmap draws the schedules of the whole simulation by elapsed period and by phase within the period.
+// The period defaults to the longest period of the objects.
+func drawHeatmap(options options, params model.Params, objects model.ObjSet) {
+	fmt.Println("================================================================================")
+	fmt.Println("Drawing phase heatmap")
+	periodMillis := float64(options.heatmapPeriodMillis)
+	if periodMillis == 0 {
+		for _, obj := range objects {
+			periodMillis = max(periodMillis, obj.Period())
+		}
+	}
+	if periodMillis <= 0 {
+		fmt.Println("   No period for the phase heatmap")
+		return
+	}
+	periods := int(math.Ceil(float64(simulationEnd(params, objects)) / periodMillis))
+
+	heatmap := analysis.SchedulePhaseHeatmap(objects, periodMillis, max(periods, 1), heatmapPhaseBuckets)
+	period := time.Duration(periodMillis) * time.Millisecond
+	fmt.Printf("   Period: %s, %d periods\n", period, periods)
+	fmt.Printf("   Highest cell: %d\n", heatmap.MaxCount())
+	draw.DrawHeatmap(heatmap.Counts(), heatmap.MaxCount(), period.String(), "0", fmt.Sprintf("%d periods", periods), options.heatmapImageFileName())
+}
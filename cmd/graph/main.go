@@ -3,39 +3,171 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd/uniformity"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
 	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
 	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
 	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/prediction"
 )
 
 const (
-	defaultArgGraphStartTime = "24h"
-	defaultArgGraphLength    = "60m"
-	defaultArgImageFileName  = "out.png"
+	defaultArgGraphStartTime   = "24h"
+	defaultArgGraphLength      = "60m"
+	defaultArgImageFileName    = "out.png"
+	defaultArgBand             = "5:95"
+	defaultMetricsBucketWidth  = "1s"
+	defaultMetricsWindow       = "10s"
+	defaultPeakRateWindows     = "1s,10s,60s"
+	defaultSpectrumBucketWidth = "1s"
+	peakRateWindowSeparator    = ","
+	csvFileSeparator           = ","
+	bandSeparator              = ":"
 )
 
+var waitPercentiles = []float64{50, 90, 99}
+
+// unpredictableParams are the parameters of simulation features that the prediction doesn't model.
+var unpredictableParams = []string{"workers", "restarts", "restart-mtbf", "watch-events"}
+
 func main() {
 
 	options := parseCLIArguments(os.Args)
 
-	fileAlreadyExists, err := cmd.FileExists(options.imageFileName)
-	if err != nil {
-		fmt.Println("Error checking if image file exists:", err)
+	for _, outputFileName := range options.outputFileNames() {
+		fileAlreadyExists, err := cmd.FileExists(outputFileName)
+		if err != nil {
+			fmt.Println("Error checking if output file exists:", err)
+			os.Exit(1)
+		}
+		if fileAlreadyExists {
+			if !options.overwriteImageFile {
+				fmt.Printf("Output file already exists: %s\n", outputFileName)
+				os.Exit(1)
+			}
+		}
+	}
+
+	var graphStartTimeMillis int = cmd.SecondsToMillis(options.graphStartTimeSeconds)
+	var graphLengthMillis int = cmd.SecondsToMillis(options.graphLengthSeconds)
+	bucketCount := 1000
+
+	if graphLengthMillis%bucketCount != 0 {
+		panic("graphLengthMillis must be divisible by bucketCount")
+	}
+
+	timePerBucket := graphLengthMillis / bucketCount // In this case division is always possible!
+	if graphLengthMillis%options.metricsBucketWidthMillis != 0 {
+		fmt.Println("The graph length must be a multiple of --metrics-bucket-width")
 		os.Exit(1)
 	}
-	if fileAlreadyExists {
-		if !options.overwriteImageFile {
-			fmt.Printf("Image file already exists: %s\n", options.imageFileName)
+	// The default windows that don't fit into a short graph are left out
+	var peakRateWindowsMillis []int
+	for _, windowMillis := range options.peakRateWindowsMillis {
+		if windowMillis <= graphLengthMillis {
+			peakRateWindowsMillis = append(peakRateWindowsMillis, windowMillis)
+		} else if options.explicitPeakRateWindows {
+			fmt.Println("The windows of --peak-rate-windows must not be longer than the graph")
 			os.Exit(1)
 		}
 	}
+	options.peakRateWindowsMillis = peakRateWindowsMillis
+
+	var results []uniformity.Result
+	var reports []report
+	var spectra []spectrum
+	addMetrics := func(csvFileName string, objects model.ObjSet) {
+		if r, ok := calculateMetrics(options, csvFileName, objects, graphStartTimeMillis, graphLengthMillis); ok {
+			reports = append(reports, r)
+		}
+	}
+	addSpectrum := func(csvFileName string, params model.Params, objects model.ObjSet) {
+		if !options.spectrum {
+			return
+		}
+		if s, ok := analyseSpectrum(options, csvFileName, params, objects, graphStartTimeMillis, graphLengthMillis); ok {
+			spectra = append(spectra, s)
+		}
+	}
+	if len(options.csvFileNames) == 1 {
+		params, objects := readSimulation(options.csvFileNames[0])
+		hist, retries := calculateHistograms(objects, graphStartTimeMillis, timePerBucket, bucketCount)
+		results = append(results, measureUniformity(options, options.csvFileNames[0], params, objects))
+		addMetrics(options.csvFileNames[0], objects)
+		printMetrics(reports)
+		addSpectrum(options.csvFileNames[0], params, objects)
+
+		var predicted []float64
+		if options.predict {
+			predicted = predict(params, objects, float64(graphStartTimeMillis), float64(timePerBucket), bucketCount)
+		}
+
+		fmt.Println("================================================================================")
+		fmt.Println("Drawing histogram")
+		draw.DrawWithPrediction(hist, retries, predicted, options.argGraphStartTime, options.argGraphLength, options.imageFileName)
+
+		if options.heatmap {
+			drawHeatmap(options, params, objects)
+		}
+		if options.objects {
+			analyseObjects(options, objects)
+		}
+	} else {
+		var runs [][]int
+		for _, csvFileName := range options.csvFileNames {
+			params, objects := readSimulation(csvFileName)
+			hist, _ := calculateHistograms(objects, graphStartTimeMillis, timePerBucket, bucketCount)
+			results = append(results, measureUniformity(options, csvFileName, params, objects))
+			addMetrics(csvFileName, objects)
+			addSpectrum(csvFileName, params, objects)
+			runs = append(runs, hist.Data())
+		}
+		printMetrics(reports)
+		drawRuns(options, runs, timePerBucket)
+	}
+
+	drawSpectra(options, spectra)
+
+	if options.metricsFileName != "" {
+		err := writeMetrics(options.metricsFileName, reports)
+		if err != nil {
+			fmt.Printf("Error writing metrics file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if options.uniformity.FileName() != "" {
+		err := uniformity.WriteFile(options.uniformity.FileName(), results)
+		if err != nil {
+			fmt.Printf("Error writing uniformity file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if options.eventsFileName != "" {
+		drawQueueing(options, float64(graphStartTimeMillis), float64(timePerBucket), bucketCount)
+	}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Done")
+}
 
+// readSimulation reads the simulation parameters and the objects from the CSV file.
+func readSimulation(csvFileName string) (model.Params, model.ObjSet) {
 	fmt.Println("================================================================================")
-	fmt.Println("Reding input data from CSV file...")
-	file, err := os.Open(options.csvFileName)
-	objects, err := model.UnmarshalObjSet(file)
+	fmt.Println("Reding input data from CSV file", csvFileName)
+	file, err := os.Open(csvFileName)
+	if err != nil {
+		fmt.Println("Error opening input file:", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	params, objects, err := model.Unmarshal(file)
 	if err != nil {
 		fmt.Println("Error reading input file:", err)
 		os.Exit(1)
@@ -43,38 +175,167 @@ func main() {
 
 	objCount := len(objects)
 	fmt.Println("   Read", objCount, "objects")
+	for _, key := range params.Keys() {
+		fmt.Printf("   %s: %s\n", key, params[key])
+	}
+	return params, objects
+}
 
+// calculateHistograms returns the histograms of all schedules and of the retries.
+func calculateHistograms(objects model.ObjSet, graphStartTimeMillis, timePerBucket, bucketCount int) (*histogram.Histogram, *histogram.Histogram) {
 	fmt.Println("================================================================================")
 	fmt.Println("Calculating the histogram...")
-	var graphStartTimeMillis int = cmd.SecondsToMillis(options.graphStartTimeSeconds)
-	var graphLengthMillis int = cmd.SecondsToMillis(options.graphLengthSeconds)
-	bucketCount := 1000
+	graphLengthMillis := timePerBucket * bucketCount
+	hist := analysis.ScheduleHistogram(objects, graphStartTimeMillis, timePerBucket, bucketCount)
+	retries := analysis.RetryHistogram(objects, graphStartTimeMillis, timePerBucket, bucketCount)
 
-	if graphLengthMillis%bucketCount != 0 {
-		panic("graphLengthMillis must be divisible by bucketCount")
-	}
+	expectedSchedules := objects.ExpectedSchedules(float64(graphStartTimeMillis), float64(graphLengthMillis)) // Assuming perfectly uniform distribution
+	fmt.Println("   Expected schedules:", int(expectedSchedules))
+	fmt.Println("   Total schedules:", hist.TotalCount())
+	fmt.Println("   Retries:", retries.TotalCount())
+	return hist, retries
+}
 
-	timePerBucket := graphLengthMillis / bucketCount // In this case division is always possible!
-	hist := histogram.NewHistogram(graphStartTimeMillis, timePerBucket, bucketCount)
-	for i := 0; i < objCount; i++ {
-		obj := objects[i]
-		for _, schedule := range obj.Schedules() {
-			if schedule >= float64(graphStartTimeMillis) && schedule < float64(graphStartTimeMillis+graphLengthMillis) {
-				hist.AddDataPoint(int(schedule))
+// measureUniformity measures the time to uniformity from the start of the simulation until its end.
+// For simulation results without the simulation time, the end is the latest schedule.
+func measureUniformity(options options, csvFileName string, params model.Params, objects model.ObjSet) uniformity.Result {
+	fmt.Println("================================================================================")
+	fmt.Println("Measuring the time to uniformity...")
+	res := options.uniformity.Measure(csvFileName, objects, simulationEnd(params, objects))
+	res.Print()
+	return res
+}
+
+// simulationEnd returns the simulation time of the parameters, or the latest schedule of older CSV files without it.
+func simulationEnd(params model.Params, objects model.ObjSet) int {
+	untilMillis, err := cmd.AsMillis(params["simulation-time"])
+	if err != nil {
+		untilMillis = 0
+		for _, obj := range objects {
+			if _, ok := obj.FirstSchedule(); ok {
+				untilMillis = max(untilMillis, int(obj.LastSchedule()))
 			}
 		}
 	}
+	return untilMillis
+}
 
-	expectedSchedules := float64(objCount) / model.AverageScheduleTime * float64(graphLengthMillis) // Assuming perfectly uniform distribution
-	fmt.Println("   Expected schedules:", int(expectedSchedules))
-	fmt.Println("   Total schedules:", hist.TotalCount())
+// predict returns the expected count of every bucket under the probabilistic jitter model.
+// It exits if the simulation used anything the prediction doesn't model.
+func predict(params model.Params, objects model.ObjSet, fromMillis, bucketWidthMillis float64, bucketCount int) []float64 {
+	fmt.Println("================================================================================")
+	fmt.Println("Predicting the histogram...")
+	for _, key := range unpredictableParams {
+		if _, ok := params[key]; ok {
+			fmt.Printf("Cannot predict a simulation with %s\n", key)
+			os.Exit(1)
+		}
+	}
+	probability, okProbability := params.Float("jitter-probability")
+	magnitude, okMagnitude := params.Float("jitter-magnitude")
+	if !okProbability || !okMagnitude {
+		fmt.Println("Cannot predict a simulation with several object classes")
+		os.Exit(1)
+	}
+	switch strategy := params["jitter-strategy"]; strategy {
+	case "probabilistic-period":
+	case "uniform":
+		probability = 1
+	default:
+		fmt.Printf("Cannot predict a simulation with the %s jitter strategy\n", strategy)
+		os.Exit(1)
+	}
+
+	res := prediction.New(objects, probability, magnitude).ExpectedCounts(fromMillis, bucketWidthMillis, bucketCount)
+	total, highest := 0.0, 0.0
+	for _, val := range res {
+		total += val
+		highest = max(highest, val)
+	}
+	fmt.Printf("   Predicted schedules: %.0f\n", total)
+	fmt.Printf("   Predicted highest bucket: %.1f\n", highest)
+	return res
+}
 
+// drawRuns draws the mean histogram of several runs, with a band between the minimum and maximum
+// and a narrower band between the percentiles of every bucket.
+func drawRuns(options options, runs [][]int, bucketWidthMillis int) {
 	fmt.Println("================================================================================")
-	fmt.Println("Drawing histogram")
-	draw.Draw(hist, options.argGraphStartTime, options.argGraphLength, options.imageFileName)
+	fmt.Printf("Summarizing %d runs\n", len(runs))
+	stats := analysis.AcrossRuns(runs, options.bandLowPercentile, options.bandHighPercentile)
+	peaks := make([]float64, len(runs))
+	meanPeak := 0.0
+	for i, run := range runs {
+		for _, count := range run {
+			peaks[i] = max(peaks[i], float64(count))
+		}
+		meanPeak += peaks[i] / float64(len(runs))
+	}
+	fmt.Printf("   Highest bucket of a run: mean %.1f, p%s %.1f, p%s %.1f, min %.0f, max %.0f\n", meanPeak,
+		formatPercentile(options.bandLowPercentile), analysis.Percentile(peaks, options.bandLowPercentile),
+		formatPercentile(options.bandHighPercentile), analysis.Percentile(peaks, options.bandHighPercentile),
+		analysis.Percentile(peaks, 0), analysis.Percentile(peaks, 100))
 
 	fmt.Println("================================================================================")
-	fmt.Println("Done")
+	fmt.Println("Drawing mean histogram with bands")
+	for _, values := range [][]float64{stats.Mean, stats.Min, stats.Max, stats.Low, stats.High} {
+		for i := range values {
+			values[i] = analysis.PerSecond(values[i], bucketWidthMillis)
+		}
+	}
+	draw.DrawBands([]draw.Band{
+		{Label: "min-max", Low: stats.Min, High: stats.Max, R: 0, G: 110.0 / 255.0, B: 0, A: 0.5},
+		{Label: "p" + formatPercentile(options.bandLowPercentile) + "-p" + formatPercentile(options.bandHighPercentile), Low: stats.Low, High: stats.High, R: 0, G: 200.0 / 255.0, B: 0, A: 0.6},
+	}, draw.Line{Label: "mean", Values: stats.Mean, R: 1, G: 1, B: 1}, "/s", options.argGraphStartTime, options.argGraphLength, options.imageFileName)
+}
+
+func formatPercentile(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// drawQueueing reads the reconciliations from the events file and draws the queue depth and the wait time percentiles.
+func drawQueueing(options options, fromMillis, bucketWidthMillis float64, bucketCount int) {
+	fmt.Println("================================================================================")
+	fmt.Println("Reading reconciliations from events file...")
+	file, err := os.Open(options.eventsFileName)
+	if err != nil {
+		fmt.Println("Error opening events file:", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	reconciles, err := model.UnmarshalReconciles(file)
+	if err != nil {
+		fmt.Println("Error reading events file:", err)
+		os.Exit(1)
+	}
+
+	var waits, bucketDelays, backoffDelays []float64
+	for _, r := range reconciles {
+		if r.Start >= fromMillis && r.Start < fromMillis+bucketWidthMillis*float64(bucketCount) {
+			waits = append(waits, r.Wait())
+			bucketDelays = append(bucketDelays, r.BucketDelay)
+			backoffDelays = append(backoffDelays, r.BackoffDelay)
+		}
+	}
+	fmt.Println("   Reconciliations started in the graph window:", len(waits))
+	for _, p := range waitPercentiles {
+		fmt.Printf("   p%.0f: wait for worker: %.1f ms, token bucket delay: %.1f ms, item backoff delay: %.1f ms\n",
+			p, analysis.Percentile(waits, p), analysis.Percentile(bucketDelays, p), analysis.Percentile(backoffDelays, p))
+	}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Drawing queue depth and wait time")
+	queueDepth := analysis.QueueDepth(reconciles, fromMillis, bucketWidthMillis, bucketCount)
+	draw.DrawLines([]draw.Line{
+		{Label: "queue", Values: queueDepth, R: 0, G: 200.0 / 255.0, B: 0},
+	}, "", options.argGraphStartTime, options.argGraphLength, options.queueDepthImageFileName())
+
+	colors := [][3]float64{{0, 200.0 / 255.0, 0}, {1, 200.0 / 255.0, 0}, {1, 80.0 / 255.0, 80.0 / 255.0}}
+	var lines []draw.Line
+	for i, values := range analysis.WaitPercentiles(reconciles, fromMillis, bucketWidthMillis, bucketCount, waitPercentiles) {
+		lines = append(lines, draw.Line{Label: fmt.Sprintf("p%.0f", waitPercentiles[i]), Values: values, R: colors[i][0], G: colors[i][1], B: colors[i][2]})
+	}
+	draw.DrawLines(lines, "ms", options.argGraphStartTime, options.argGraphLength, options.waitTimeImageFileName())
 }
 
 func parseCLIArguments(osArgs []string) options {
@@ -82,7 +343,31 @@ func parseCLIArguments(osArgs []string) options {
 
 	if len(osArgs) < 2 {
 		fmt.Println("Reads the simulation data file and plots results as a histogram with configurable time window.")
-		fmt.Println("Usage: go run . --csv-file=<path> [--image-file=<path>] [--overwrite-image-file] --graph-start-time=<time> --graph-length=<time>")
+		fmt.Println("Usage: go run . --csv-file=<path> [--image-file=<path>] [--overwrite-image-file] --graph-start-time=<time> --graph-length=<time> [--events-file=<path>]")
+		fmt.Println("With --events-file, the queue depth and the wait time percentiles are drawn to <image-file>-queue-depth.png and <image-file>-wait-time.png")
+		fmt.Println("The CSV file may also be a comma-separated list of files or a pattern, e.g. 'simulation-run*.csv', with the results of several runs.")
+		fmt.Println("Then the mean of all runs is drawn, with a band between the minimum and maximum, and a band between the percentiles")
+		fmt.Println("of --band=<low>:<high> (default: " + defaultArgBand + ")")
+		fmt.Println("With --predict, the expected count of every bucket under the probabilistic jitter model is drawn over the histogram of a single CSV file")
+		fmt.Println("With --heatmap, the schedules of the whole simulation of a single CSV file are drawn to <image-file>-heatmap.png,")
+		fmt.Println("by elapsed period and by phase within the period of --heatmap-period=<time> (default: the longest period of the objects)")
+		fmt.Println("With --objects, the intervals between the schedules of every object of a single CSV file, how many are jittered,")
+		fmt.Println("and how far every object drifted from the grid of its first schedule and its period are summarized,")
+		fmt.Println("and the histogram of the jittered intervals is drawn to <image-file>-intervals.png")
+		fmt.Println("   --objects-file=<path>          Also stores the statistics of every object in a CSV file")
+		fmt.Println("With --spectrum, the power spectrum of the whole periods of the graph window is drawn to <image-file>-spectrum.png,")
+		fmt.Println("and the synchronisation index of every window of " + strconv.Itoa(synchronisationWindowPeriods) + " periods over the whole simulation to <image-file>-synchronisation.png:")
+		fmt.Println("the share of the power at the period and its harmonics. Several CSV files are drawn as several lines")
+		fmt.Println("   --spectrum-period=<time>       The base period (default: the longest period of the objects)")
+		fmt.Println("   --spectrum-bucket-width=<time> Width of the buckets of the load, a divisor of the period (default: " + defaultSpectrumBucketWidth + ")")
+		fmt.Println("The load metrics of the graph window are printed for every CSV file:")
+		fmt.Println("   --metrics-bucket-width=<time>  Width of the buckets the metrics are computed from (default: " + defaultMetricsBucketWidth + ")")
+		fmt.Println("   --metrics-window=<time>        Length of the sliding window with the most schedules, a multiple of the bucket width (default: " + defaultMetricsWindow + ")")
+		fmt.Println("   --peak-rate-windows=<list>     Lengths of the sliding windows of the peak rates in events per second,")
+		fmt.Println("                                  multiples of the bucket width (default: " + defaultPeakRateWindows + ", those that fit)")
+		fmt.Println("   --metrics-file=<path>          Stores the load metrics in a JSON file")
+		fmt.Println("The time to uniformity of every CSV file is measured with the options:")
+		uniformity.PrintUsage()
 		fmt.Println("Example: go run . --csv-file=simulation.csv --image-file=out.png --graph-start-time=4m --graph-length=4h")
 		os.Exit(1)
 	}
@@ -97,7 +382,27 @@ func parseCLIArguments(osArgs []string) options {
 		fmt.Println("Missing argument --csv-file")
 		os.Exit(1)
 	}
-	res.csvFileName = argCSVFileName
+	for _, pattern := range strings.Split(argCSVFileName, csvFileSeparator) {
+		fileNames, err := filepath.Glob(pattern)
+		if err != nil || len(fileNames) == 0 {
+			fmt.Printf("No CSV file matches: %s\n", pattern)
+			os.Exit(1)
+		}
+		res.csvFileNames = append(res.csvFileNames, fileNames...)
+	}
+
+	argBand, ok := args.Get("--band")
+	if !ok {
+		argBand = defaultArgBand
+	}
+	argLow, argHigh, _ := strings.Cut(argBand, bandSeparator)
+	low, errLow := strconv.ParseFloat(argLow, 64)
+	high, errHigh := strconv.ParseFloat(argHigh, 64)
+	if errLow != nil || errHigh != nil || low < 0 || low > high || high > 100 {
+		fmt.Printf("Invalid argument value for --band: %s\n", argBand)
+		os.Exit(1)
+	}
+	res.bandLowPercentile, res.bandHighPercentile = low, high
 
 	argImageFileName, ok := args.Get("--image-file")
 	if !ok {
@@ -132,15 +437,181 @@ func parseCLIArguments(osArgs []string) options {
 	}
 	res.graphLengthSeconds = graphLengthSeconds
 
+	res.eventsFileName, _ = args.Get("--events-file")
+
+	argMetricsBucketWidth, ok := args.Get("--metrics-bucket-width")
+	if !ok {
+		argMetricsBucketWidth = defaultMetricsBucketWidth
+	}
+	res.metricsBucketWidthMillis, err = cmd.AsMillis(argMetricsBucketWidth)
+	if err != nil || res.metricsBucketWidthMillis <= 0 {
+		fmt.Printf("Invalid argument value for --metrics-bucket-width: %s\n", argMetricsBucketWidth)
+		os.Exit(1)
+	}
+
+	argMetricsWindow, ok := args.Get("--metrics-window")
+	if !ok {
+		argMetricsWindow = defaultMetricsWindow
+	}
+	res.metricsWindowMillis, err = cmd.AsMillis(argMetricsWindow)
+	if err != nil || res.metricsWindowMillis <= 0 || res.metricsWindowMillis%res.metricsBucketWidthMillis != 0 {
+		fmt.Printf("Invalid argument value for --metrics-window: %s\n", argMetricsWindow)
+		os.Exit(1)
+	}
+
+	argPeakRateWindows, ok := args.Get("--peak-rate-windows")
+	res.explicitPeakRateWindows = ok
+	if !ok {
+		argPeakRateWindows = defaultPeakRateWindows
+	}
+	for _, argWindow := range strings.Split(argPeakRateWindows, peakRateWindowSeparator) {
+		windowMillis, err := cmd.AsMillis(argWindow)
+		if !ok && err == nil && windowMillis%res.metricsBucketWidthMillis != 0 {
+			continue // A default window that doesn't fit the --metrics-bucket-width
+		}
+		if err != nil || windowMillis <= 0 || windowMillis%res.metricsBucketWidthMillis != 0 {
+			fmt.Printf("Invalid argument value for --peak-rate-windows: %s\n", argPeakRateWindows)
+			os.Exit(1)
+		}
+		res.peakRateWindowsMillis = append(res.peakRateWindowsMillis, windowMillis)
+	}
+
+	res.metricsFileName, _ = args.Get("--metrics-file")
+
+	res.uniformity = uniformity.ParseOptions(args)
+
+	_, ok = args.Get("--predict")
+	res.predict = ok
+
+	_, ok = args.Get("--heatmap")
+	res.heatmap = ok
+	argHeatmapPeriod, ok := args.Get("--heatmap-period")
+	if ok {
+		res.heatmapPeriodMillis, err = cmd.AsMillis(argHeatmapPeriod)
+		if err != nil || res.heatmapPeriodMillis <= 0 {
+			fmt.Printf("Invalid argument value for --heatmap-period: %s\n", argHeatmapPeriod)
+			os.Exit(1)
+		}
+	}
+
+	_, ok = args.Get("--spectrum")
+	res.spectrum = ok
+	argSpectrumPeriod, ok := args.Get("--spectrum-period")
+	if ok {
+		res.spectrumPeriodMillis, err = cmd.AsMillis(argSpectrumPeriod)
+		if err != nil || res.spectrumPeriodMillis <= 0 {
+			fmt.Printf("Invalid argument value for --spectrum-period: %s\n", argSpectrumPeriod)
+			os.Exit(1)
+		}
+	}
+	argSpectrumBucketWidth, ok := args.Get("--spectrum-bucket-width")
+	if !ok {
+		argSpectrumBucketWidth = defaultSpectrumBucketWidth
+	}
+	res.spectrumBucketWidthMillis, err = cmd.AsMillis(argSpectrumBucketWidth)
+	if err != nil || res.spectrumBucketWidthMillis <= 0 {
+		fmt.Printf("Invalid argument value for --spectrum-bucket-width: %s\n", argSpectrumBucketWidth)
+		os.Exit(1)
+	}
+
+	_, ok = args.Get("--objects")
+	res.objects = ok
+	res.objectsFileName, ok = args.Get("--objects-file")
+	if ok {
+		res.objects = true
+	}
+	if res.objects && len(res.csvFileNames) > 1 {
+		fmt.Println("--objects needs a single CSV file")
+		os.Exit(1)
+	}
+
+	if res.heatmap && len(res.csvFileNames) > 1 {
+		fmt.Println("--heatmap needs a single CSV file")
+		os.Exit(1)
+	}
+
+	if res.predict && len(res.csvFileNames) > 1 {
+		fmt.Println("--predict needs a single CSV file")
+		os.Exit(1)
+	}
+
 	return res
 }
 
 type options struct {
-	csvFileName           string
-	imageFileName         string
-	overwriteImageFile    bool
-	argGraphStartTime     string
-	graphStartTimeSeconds int
-	argGraphLength        string
-	graphLengthSeconds    int
+	csvFileNames              []string
+	bandLowPercentile         float64
+	bandHighPercentile        float64
+	imageFileName             string
+	overwriteImageFile        bool
+	argGraphStartTime         string
+	graphStartTimeSeconds     int
+	argGraphLength            string
+	graphLengthSeconds        int
+	eventsFileName            string
+	predict                   bool
+	heatmap                   bool
+	heatmapPeriodMillis       int
+	objects                   bool
+	objectsFileName           string
+	spectrum                  bool
+	spectrumPeriodMillis      int
+	spectrumBucketWidthMillis int
+	metricsBucketWidthMillis  int
+	metricsWindowMillis       int
+	peakRateWindowsMillis     []int
+	explicitPeakRateWindows   bool
+	metricsFileName           string
+	uniformity                uniformity.Options
+}
+
+// outputFileNames returns the names of all files written with these options.
+func (o options) outputFileNames() []string {
+	res := []string{o.imageFileName}
+	if o.eventsFileName != "" {
+		res = append(res, o.queueDepthImageFileName(), o.waitTimeImageFileName())
+	}
+	if o.heatmap {
+		res = append(res, o.heatmapImageFileName())
+	}
+	if o.objects {
+		res = append(res, o.intervalsImageFileName())
+	}
+	if o.objectsFileName != "" {
+		res = append(res, o.objectsFileName)
+	}
+	if o.spectrum {
+		res = append(res, o.spectrumImageFileName(), o.synchronisationImageFileName())
+	}
+	if o.uniformity.FileName() != "" {
+		res = append(res, o.uniformity.FileName())
+	}
+	if o.metricsFileName != "" {
+		res = append(res, o.metricsFileName)
+	}
+	return res
+}
+
+func (o options) queueDepthImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-queue-depth")
+}
+
+func (o options) waitTimeImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-wait-time")
+}
+
+func (o options) heatmapImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-heatmap")
+}
+
+func (o options) intervalsImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-intervals")
+}
+
+func (o options) spectrumImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-spectrum")
+}
+
+func (o options) synchronisationImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-synchronisation")
 }
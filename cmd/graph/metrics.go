@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// report are the load metrics of the graph window of one simulation.
+type report struct {
+	Source string `json:"source"`
+	analysis.LoadMetrics
+	PeakRates []analysis.PeakRate `json:"peakRates"`
+}
+
+// calculateMetrics computes the load metrics of the graph window, from a histogram with the buckets of the metrics options.
+// It returns false if there are no schedules in the window.
+func calculateMetrics(options options, csvFileName string, objects model.ObjSet, fromMillis, lengthMillis int) (report, bool) {
+	hist := analysis.ScheduleHistogram(objects, fromMillis, options.metricsBucketWidthMillis, lengthMillis/options.metricsBucketWidthMillis)
+	metrics, err := analysis.NewLoadMetrics(hist, options.metricsWindowMillis)
+	if err != nil {
+		fmt.Printf("   No load metrics for %s: %v\n", csvFileName, err)
+		return report{}, false
+	}
+	peakRates, err := analysis.PeakRates(hist, options.peakRateWindowsMillis)
+	if err != nil {
+		fmt.Printf("   No peak rates for %s: %v\n", csvFileName, err)
+		return report{}, false
+	}
+	return report{Source: csvFileName, LoadMetrics: metrics, PeakRates: peakRates}, true
+}
+
+// metricRow is a row of the metrics table, with the value of a report.
+type metricRow struct {
+	label string
+	value func(r report) string
+}
+
+// printMetrics prints the load metrics as a table, with a column for every simulation.
+func printMetrics(reports []report) {
+	fmt.Println("================================================================================")
+	fmt.Println("Load metrics of the graph window")
+	if len(reports) == 0 {
+		return
+	}
+	rows := []metricRow{
+		{"Schedules", func(r report) string { return strconv.Itoa(r.Total) }},
+		{"Buckets", func(r report) string { return fmt.Sprintf("%d of %dms", r.Buckets, r.BucketWidthMillis) }},
+		{"Peak/mean", func(r report) string { return fmt.Sprintf("%.3f", r.PeakToMean) }},
+		{"Coefficient of variation", func(r report) string { return fmt.Sprintf("%.3f", r.CoefficientOfVariation) }},
+		{"Max in " + strconv.Itoa(reports[0].WindowMillis) + "ms window", func(r report) string { return strconv.Itoa(r.MaxInWindow) }},
+		{"Bucket p50", func(r report) string { return fmt.Sprintf("%.1f", r.P50) }},
+		{"Bucket p90", func(r report) string { return fmt.Sprintf("%.1f", r.P90) }},
+		{"Bucket p99", func(r report) string { return fmt.Sprintf("%.1f", r.P99) }},
+		{"Bucket max", func(r report) string { return fmt.Sprintf("%.0f", r.Max) }},
+		{"Chi-square", func(r report) string {
+			return fmt.Sprintf("%.1f (%d degrees of freedom)", r.ChiSquare, r.DegreesOfFreedom)
+		}},
+		{"Chi-square p-value", func(r report) string { return fmt.Sprintf("%.4g", r.PValue) }},
+	}
+	for i, peakRate := range reports[0].PeakRates {
+		rows = append(rows, metricRow{"Peak rate in " + strconv.Itoa(peakRate.WindowMillis) + "ms window", func(r report) string {
+			return fmt.Sprintf("%.2f/s", r.PeakRates[i].PerSecond)
+		}})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "   Metric")
+	for _, r := range reports {
+		fmt.Fprintf(w, "\t%s", filepath.Base(r.Source))
+	}
+	fmt.Fprintln(w)
+	for _, row := range rows {
+		fmt.Fprintf(w, "   %s", row.label)
+		for _, r := range reports {
+			fmt.Fprintf(w, "\t%s", row.value(r))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+// writeMetrics stores the load metrics of all simulations in a JSON file.
+func writeMetrics(fileName string, reports []report) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(data, '\n'), 0644)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// intervalBuckets is the number of buckets of the interval histogram, one pixel each.
+const intervalBuckets = 1000
+
+// analyseObjects prints the summary of the timelines of all objects, draws the histogram of the off-period intervals,
+// and writes the statistics of every object to the objects file, if there is one.
+func analyseObjects(options options, objects model.ObjSet) {
+	fmt.Println("================================================================================")
+	fmt.Println("Analysing the objects...")
+	var stats []analysis.ObjectStats
+	for _, obj := range objects {
+		stats = append(stats, analysis.NewObjectStats(obj))
+	}
+	population := analysis.NewPopulationStats(stats)
+	fmt.Printf("   Objects: %d\n", population.Objects)
+	fmt.Printf("   Intervals: %d, off-period %d (%.2f%%)\n", population.Intervals.Count, population.OffPeriod, 100*population.OffPeriodShare)
+	printSummary("Interval", population.Intervals, formatMillis)
+	printSummary("Off-period intervals per object", population.OffPeriodPerObject, func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) })
+	printSummary("Drift from the grid", population.Drift, formatMillis)
+
+	drawIntervals(options, stats)
+
+	if options.objectsFileName != "" {
+		err := writeObjects(options.objectsFileName, stats)
+		if err != nil {
+			fmt.Printf("Error writing objects file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func printSummary(label string, summary analysis.Summary, format func(float64) string) {
+	if summary.Count == 0 {
+		fmt.Printf("   %s: none\n", label)
+		return
+	}
+	fmt.Printf("   %s: mean %s, std dev %s, min %s, p1 %s, p50 %s, p99 %s, max %s\n", label, format(summary.Mean), format(summary.StdDev),
+		format(summary.Min), format(summary.P1), format(summary.P50), format(summary.P99), format(summary.Max))
+}
+
+// formatMillis formats a time in milliseconds as a duration, rounded to milliseconds.
+func formatMillis(millis float64) string {
+	return (time.Duration(millis * float64(time.Millisecond))).Round(time.Millisecond).String()
+}
+
+// drawIntervals draws the histogram of the off-period intervals of all objects. The other intervals are all one period.
+func drawIntervals(options options, stats []analysis.ObjectStats) {
+	var intervals []float64
+	for _, s := range stats {
+		intervals = append(intervals, s.OffPeriodIntervals()...)
+	}
+	if len(intervals) == 0 {
+		fmt.Println("   No off-period intervals to draw")
+		return
+	}
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, interval := range intervals {
+		lowest = min(lowest, interval)
+		highest = max(highest, interval)
+	}
+	from := int(math.Floor(lowest))
+	width := max(1, int(math.Ceil(float64(int(math.Floor(highest))-from+1)/intervalBuckets)))
+	hist := histogram.NewHistogram(from, width, intervalBuckets)
+	for _, interval := range intervals {
+		hist.AddDataPoint(int(math.Floor(interval)))
+	}
+
+	fmt.Println("   Drawing the histogram of the off-period intervals")
+	draw.DrawDistribution(hist, formatMillis(float64(from)), formatMillis(float64(from+width*intervalBuckets)), options.intervalsImageFileName())
+}
+
+// writeObjects stores the statistics of every object in a CSV file, with the times in milliseconds.
+func writeObjects(fileName string, stats []analysis.ObjectStats) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintln(file, "id,period,intervals,off-period,drift,interval-mean,interval-std-dev,interval-min,interval-max")
+	if err != nil {
+		return err
+	}
+	for _, s := range stats {
+		summary := analysis.Summarize(s.Intervals)
+		values := []float64{s.Period, float64(len(s.Intervals)), float64(s.OffPeriod), s.Drift, summary.Mean, summary.StdDev, summary.Min, summary.Max}
+		line := strconv.Itoa(s.ID)
+		for _, val := range values {
+			line += ","
+			if !math.IsNaN(val) {
+				line += strconv.FormatFloat(val, 'f', -1, 64)
+			}
+		}
+		_, err = fmt.Fprintln(file, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	spectrumHarmonics            = 10 // Harmonics of the period drawn in the power spectrum
+	synchronisationWindowPeriods = 12
+)
+
+// spectrum is the spectral analysis of one simulation, in percent of the power of the load without its mean.
+type spectrum struct {
+	label           string
+	power           []float64 // Relative power of the graph window, from frequency 0 up to the last harmonic of the period
+	synchronisation []float64 // Synchronisation index of the windows starting at every period
+	periodMillis    int
+}
+
+// analyseSpectrum computes the power spectrum of the whole periods of the graph window,
+// and the synchronisation index over the whole simulation. The period defaults to the longest period of the objects.
+// It returns false if the period is not a multiple of the bucket width, or the graph is shorter than the period.
+func analyseSpectrum(options options, csvFileName string, params model.Params, objects model.ObjSet, graphStartMillis, graphLengthMillis int) (spectrum, bool) {
+	fmt.Println("================================================================================")
+	fmt.Println("Analysing the spectrum...")
+	periodMillis := options.spectrumPeriodMillis
+	if periodMillis == 0 {
+		for _, obj := range objects {
+			periodMillis = max(periodMillis, int(obj.Period()))
+		}
+	}
+	if periodMillis <= 0 || periodMillis%options.spectrumBucketWidthMillis != 0 {
+		fmt.Printf("   The period of %dms is not a multiple of the spectrum bucket width of %dms\n", periodMillis, options.spectrumBucketWidthMillis)
+		return spectrum{}, false
+	}
+	periodBuckets := periodMillis / options.spectrumBucketWidthMillis
+	periods := graphLengthMillis / periodMillis
+	if periods == 0 {
+		fmt.Printf("   The graph is shorter than the period of %s\n", time.Duration(periodMillis)*time.Millisecond)
+		return spectrum{}, false
+	}
+
+	graph := analysis.ScheduleHistogram(objects, graphStartMillis, options.spectrumBucketWidthMillis, periods*periodBuckets)
+	endMillis := simulationEnd(params, objects)
+	whole := analysis.ScheduleHistogram(objects, 0, options.spectrumBucketWidthMillis, endMillis/options.spectrumBucketWidthMillis)
+
+	res := spectrum{label: filepath.Base(csvFileName), periodMillis: periodMillis}
+	for _, share := range analysis.RelativePowerSpectrum(graph.Data()) {
+		if len(res.power) > spectrumHarmonics*periods {
+			break
+		}
+		res.power = append(res.power, 100*share)
+	}
+	for _, index := range analysis.SynchronisationOverTime(whole.Data(), periodBuckets, synchronisationWindowPeriods, 0) {
+		res.synchronisation = append(res.synchronisation, 100*index)
+	}
+
+	fmt.Printf("   Period: %s, %d whole periods in the graph\n", time.Duration(periodMillis)*time.Millisecond, periods)
+	fmt.Printf("   Synchronisation index of the graph: %.2f%% (about %.2f%% for a random load)\n", 100*analysis.SynchronisationIndex(graph.Data(), periodBuckets, 0), 100/float64(periods))
+	return res, true
+}
+
+// drawSpectra draws the power spectra of the simulations, and their synchronisation index over time up to the start of the last window.
+func drawSpectra(options options, spectra []spectrum) {
+	if len(spectra) == 0 {
+		return
+	}
+	fmt.Println("================================================================================")
+	fmt.Println("Drawing power spectrum and synchronisation index")
+	var powerLines, synchronisationLines []draw.Line
+	for i, s := range spectra {
+		r, g, b := draw.LineColor(i)
+		powerLines = append(powerLines, draw.Line{Label: s.label, Values: s.power, R: r, G: g, B: b})
+		synchronisationLines = append(synchronisationLines, draw.Line{Label: s.label, Values: s.synchronisation, R: r, G: g, B: b})
+	}
+	draw.DrawLinesOver(powerLines, "%", "0", fmt.Sprintf("%d per period", spectrumHarmonics), options.spectrumImageFileName())
+	lastWindowStart := max(len(spectra[0].synchronisation)-1, 0) * spectra[0].periodMillis
+	draw.DrawLines(synchronisationLines, "%", "0s", fmt.Sprintf("%ds", lastWindowStart/1000), options.synchronisationImageFileName())
+}
@@ -2,74 +2,67 @@ package main
 
 import (
 	"fmt"
-
-	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
-	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
-
 	"math/rand/v2"
 	"os"
 	"strconv"
-)
 
-const (
-	defaultArgSimulationTime = "24h"
-	defaultSpreadPercent     = "0.02" // 2%
-	defaultObjectCount       = "1000"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd/simulation"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd/uniformity"
 )
 
 func main() {
 
 	opts := parseCLIArguments(os.Args)
 
-	if opts.objCount <= 100 || opts.objCount > 100000 {
-		fmt.Printf("Object count must be between 100 and 100000\n")
-		os.Exit(1)
-	}
-
-	fileExists, err := cmd.FileExists(opts.csvFileName)
-	if err != nil {
-		fmt.Println("Error checking if csv file exists:", err)
-		os.Exit(1)
-	}
-	if fileExists {
-		if !opts.overwriteCsvFile {
-			fmt.Printf("File %s already exists. Please remove it or choose another file name.\n", opts.csvFileName)
-			os.Exit(1)
+	for _, run := range opts.runs() {
+		for _, fileName := range []string{run.csvFileName, run.EventsFileName(), run.uniformity.FileName()} {
+			if fileName == "" {
+				continue
+			}
+			fileExists, err := cmd.FileExists(fileName)
+			if err != nil {
+				fmt.Println("Error checking if output file exists:", err)
+				os.Exit(1)
+			}
+			if fileExists && !opts.overwriteCsvFile {
+				fmt.Printf("File %s already exists. Please remove it or choose another file name.\n", fileName)
+				os.Exit(1)
+			}
 		}
 	}
 
 	fmt.Println("================================================================================")
-	fmt.Println("Generating the scheduling of objects over time:")
-	fmt.Printf("   Simulation time: %d:%d:%d [h:m:s]\n", opts.simulationTimeSeconds/3600, (opts.simulationTimeSeconds%3600)/60, opts.simulationTimeSeconds%60)
-	fmt.Printf("   Object count: %d\n", opts.objCount)
-	fmt.Printf("   Spread percent: %.2f\n", opts.spreadPercent)
-
-	var simulationTimeMillis int = cmd.SecondsToMillis(opts.simulationTimeSeconds)
-	var initialScheduleMillis int = cmd.MinutesToMillis(5)
-
-	var objects = model.ObjSet{}
-
-	fmt.Println("================================================================================")
-	fmt.Println("Initializing objects...")
-	rs := model.RandomSupport{
-		Float64: rand.Float64,
+	opts.PrintSummary()
+	if opts.runCount > 1 {
+		fmt.Printf("   Runs: %d\n", opts.runCount)
 	}
 
-	for i := 0; i < opts.objCount; i++ {
-		obj := model.NewObject(i, float64(initialScheduleMillis), opts.spreadPercent).SetRandomSupport(rs)
-		objects = append(objects, obj)
+	var results []uniformity.Result
+	for i, run := range opts.runs() {
+		if opts.runCount > 1 {
+			fmt.Println("================================================================================")
+			fmt.Printf("Run %d of %d, seed: %d\n", i+1, opts.runCount, run.Seed())
+		}
+		results = append(results, simulate(run))
 	}
 
-	fmt.Println("================================================================================")
-	fmt.Println("Simulating re-schedules...")
-	for i := 0; i < opts.objCount; i++ {
-		obj := objects[i]
-		// Simulate re-schedules
-		for obj.LastSchedule() < float64(simulationTimeMillis) {
-			obj.AddRandomSchedule()
+	if opts.uniformity.FileName() != "" {
+		err := uniformity.WriteFile(opts.uniformity.FileName(), results)
+		if err != nil {
+			fmt.Printf("Error writing uniformity file: %v\n", err)
+			os.Exit(1)
 		}
 	}
 
+	fmt.Println("================================================================================")
+	fmt.Println("Done")
+}
+
+// simulate runs the simulation, writes the schedules of all objects to the CSV file, and measures the time to uniformity.
+func simulate(opts options) uniformity.Result {
+	objects := simulation.Run(opts.Options, true)
+
 	fmt.Println("================================================================================")
 	fmt.Println("Writing object schedules to a file...")
 
@@ -85,10 +78,22 @@ func main() {
 		}
 	}()
 
-	objects.Marshal(file)
+	err = opts.Params().Marshal(file)
+	if err != nil {
+		fmt.Printf("Error writing CSV file: %v\n", err)
+		os.Exit(1)
+	}
+	err = objects.Marshal(file)
+	if err != nil {
+		fmt.Printf("Error writing CSV file: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("================================================================================")
-	fmt.Println("Done")
+	fmt.Println("Measuring the time to uniformity...")
+	res := opts.uniformity.Measure(opts.csvFileName, objects, opts.SimulationTimeMillis())
+	res.Print()
+	return res
 }
 
 func parseCLIArguments(osArgs []string) options {
@@ -96,14 +101,20 @@ func parseCLIArguments(osArgs []string) options {
 	res := options{}
 	if len(osArgs) < 2 {
 		fmt.Println("Runs the simulation and stores the results in a CSV file.")
-		fmt.Println("Usage: go run . --csv-file=<path> [--simulation-time=<time>] [--spread-percent=<float>] [--object-count=<uint>] [--overwrite-csv-file]")
+		fmt.Println("Usage: go run . --csv-file=<path> [options]")
+		fmt.Println("Options:")
+		simulation.PrintUsage()
+		uniformity.PrintUsage()
+		fmt.Println("   --runs=<uint>                  Repeats the simulation with independent seeds derived from --seed (default: 1)")
+		fmt.Println("                                  Every run is stored in its own file, e.g. simulation-run1.csv, and so are the events files")
+		fmt.Println("   --overwrite-csv-file           Overwrite existing output files")
 		fmt.Println("Example: go run . --csv-file=simulation.csv --simulation-time=24h --spread-percent=0.02 --object-count=1000")
 		os.Exit(1)
 	}
 
 	args := cmd.Arguments{}
-	for i := 1; i < len(os.Args); i++ {
-		args.Add(os.Args[i])
+	for i := 1; i < len(osArgs); i++ {
+		args.Add(osArgs[i])
 	}
 
 	csvFileName, ok := args.Get("--csv-file")
@@ -116,46 +127,48 @@ func parseCLIArguments(osArgs []string) options {
 	_, ok = args.Get("--overwrite-csv-file")
 	res.overwriteCsvFile = ok
 
-	argSimulationTime, ok := args.Get("--simulation-time")
-	if !ok {
-		argSimulationTime = defaultArgSimulationTime
-	}
-	simulationTimeSeconds, err := cmd.AsSeconds(argSimulationTime)
-	if err != nil {
-		fmt.Printf("Invalid argument value for --simulation-time: %s\n", argSimulationTime)
-		os.Exit(1)
-	}
-	res.simulationTimeSeconds = simulationTimeSeconds
-
-	argSpreadPercent, ok := args.Get("--spread-percent")
-	if !ok {
-		argSpreadPercent = defaultSpreadPercent
-	}
-	spreadPercent, err := strconv.ParseFloat(argSpreadPercent, 64)
-	if err != nil {
-		fmt.Printf("Invalid argument value for --spread-percent: %s\n", argSpreadPercent)
-		os.Exit(1)
+	res.runCount = 1
+	argRuns, ok := args.Get("--runs")
+	if ok {
+		runCount, err := strconv.Atoi(argRuns)
+		if err != nil || runCount < 1 {
+			fmt.Printf("Invalid argument value for --runs: %s\n", argRuns)
+			os.Exit(1)
+		}
+		res.runCount = runCount
 	}
-	res.spreadPercent = spreadPercent
 
-	argObjectCount, ok := args.Get("--object-count")
-	if !ok {
-		argObjectCount = defaultObjectCount
-	}
-	objCount, err := strconv.Atoi(argObjectCount)
-	if err != nil {
-		fmt.Printf("Invalid argument value for --object-count: %s\n", argObjectCount)
-		os.Exit(1)
-	}
-	res.objCount = objCount
+	res.Options = simulation.ParseOptions(args)
+	res.uniformity = uniformity.ParseOptions(args)
 
 	return res
 }
 
 type options struct {
-	csvFileName           string
-	overwriteCsvFile      bool
-	simulationTimeSeconds int
-	spreadPercent         float64
-	objCount              int
+	simulation.Options
+	csvFileName      string
+	overwriteCsvFile bool
+	runCount         int
+	uniformity       uniformity.Options
+}
+
+// runs returns the options of every run. With several runs, every run has its own seed, derived from the seed of the options,
+// and its own output files.
+func (o options) runs() []options {
+	if o.runCount == 1 {
+		return []options{o}
+	}
+	seeds := rand.New(rand.NewPCG(o.Seed(), o.Seed()))
+	var res []options
+	for i := 1; i <= o.runCount; i++ {
+		run := o
+		suffix := fmt.Sprintf("-run%d", i)
+		run.csvFileName = cmd.WithSuffix(o.csvFileName, suffix)
+		run.Options = o.WithSeed(seeds.Uint64())
+		if o.EventsFileName() != "" {
+			run.Options = run.WithEventsFileName(cmd.WithSuffix(o.EventsFileName(), suffix))
+		}
+		res = append(res, run)
+	}
+	return res
 }
@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	burstSeparator      = ","
+	burstFieldSeparator = ":"
+)
+
+// parseArrivals parses an arrival process in one of the formats:
+// poisson:<objects-per-second>, bursts:<at>:<count>:<length>,... or file:<path>.
+// The file contains one creation time per line, e.g. "90s" or "1500ms".
+func parseArrivals(spec string) (model.ArrivalProcess, error) {
+	kind, value, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "poisson":
+		perSecond, err := strconv.ParseFloat(value, 64)
+		if err != nil || perSecond <= 0 {
+			return nil, fmt.Errorf("invalid rate of poisson arrivals: %s", value)
+		}
+		return model.PoissonArrivals{PerSecond: perSecond}, nil
+	case "bursts":
+		return parseBursts(value)
+	case "file":
+		return readArrivals(value)
+	}
+	return nil, fmt.Errorf("invalid arrival process: %s", spec)
+}
+
+func parseBursts(spec string) (model.ArrivalProcess, error) {
+	var res model.BurstArrivals
+	for _, burstSpec := range strings.Split(spec, burstSeparator) {
+		fields := strings.Split(burstSpec, burstFieldSeparator)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid burst: %s", burstSpec)
+		}
+		atSeconds, err := cmd.AsSeconds(fields[0])
+		if err != nil || atSeconds < 0 {
+			return nil, fmt.Errorf("invalid time of burst: %s", burstSpec)
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid object count of burst: %s", burstSpec)
+		}
+		lengthSeconds, err := cmd.AsSeconds(fields[2])
+		if err != nil || lengthSeconds < 0 {
+			return nil, fmt.Errorf("invalid length of burst: %s", burstSpec)
+		}
+		res.Bursts = append(res.Bursts, model.Burst{
+			At:     float64(cmd.SecondsToMillis(atSeconds)),
+			Count:  count,
+			Length: float64(cmd.SecondsToMillis(lengthSeconds)),
+		})
+	}
+	return res, nil
+}
+
+func readArrivals(path string) (model.ArrivalProcess, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var res model.ReplayedArrivals
+	bf := bufio.NewScanner(file)
+	for bf.Scan() {
+		line := strings.TrimSpace(bf.Text())
+		if line == "" {
+			continue
+		}
+		millis, err := cmd.AsMillis(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time in %s: %s", path, line)
+		}
+		res.Times = append(res.Times, float64(millis))
+	}
+	return res, bf.Err()
+}
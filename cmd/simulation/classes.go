@@ -0,0 +1,78 @@
+package simulation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+)
+
+const (
+	classSeparator      = ","
+	classFieldSeparator = ":"
+)
+
+// objectClass describes a group of objects that share the same period and jitter.
+type objectClass struct {
+	count             int
+	periodMillis      int
+	jitterProbability float64
+	jitterMagnitude   float64
+}
+
+// parseObjectClasses parses a list of classes in the format: count:period[:jitter-probability[:jitter-magnitude]],...
+// Missing jitter values are taken from the given defaults.
+// For example: "500:30s,300:10m:0.05,200:1h:0.1:0.02"
+func parseObjectClasses(spec string, defaultJitterProbability, defaultJitterMagnitude float64) ([]objectClass, error) {
+	var res []objectClass
+	for _, classSpec := range strings.Split(spec, classSeparator) {
+		fields := strings.Split(classSpec, classFieldSeparator)
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid object class: %s", classSpec)
+		}
+
+		count, err := strconv.Atoi(fields[0])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid object count in class: %s", classSpec)
+		}
+		periodSeconds, err := cmd.AsSeconds(fields[1])
+		if err != nil || periodSeconds <= 0 {
+			return nil, fmt.Errorf("invalid period in class: %s", classSpec)
+		}
+
+		class := objectClass{
+			count:             count,
+			periodMillis:      cmd.SecondsToMillis(periodSeconds),
+			jitterProbability: defaultJitterProbability,
+			jitterMagnitude:   defaultJitterMagnitude,
+		}
+		if len(fields) > 2 {
+			class.jitterProbability, err = strconv.ParseFloat(fields[2], 64)
+			if err != nil || class.jitterProbability < 0 || class.jitterProbability > 1 {
+				return nil, fmt.Errorf("invalid jitter probability in class: %s", classSpec)
+			}
+		}
+		if len(fields) > 3 {
+			class.jitterMagnitude, err = strconv.ParseFloat(fields[3], 64)
+			if err != nil || class.jitterMagnitude < 0 || class.jitterMagnitude > 1 {
+				return nil, fmt.Errorf("invalid jitter magnitude in class: %s", classSpec)
+			}
+		}
+		res = append(res, class)
+	}
+	return res, nil
+}
+
+func (c objectClass) String() string {
+	return fmt.Sprintf("%d:%ds:%s:%s", c.count, c.periodMillis/1000,
+		strconv.FormatFloat(c.jitterProbability, 'f', -1, 64), strconv.FormatFloat(c.jitterMagnitude, 'f', -1, 64))
+}
+
+func classesString(classes []objectClass) string {
+	specs := make([]string, len(classes))
+	for i, class := range classes {
+		specs[i] = class.String()
+	}
+	return strings.Join(specs, classSeparator)
+}
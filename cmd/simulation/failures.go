@@ -0,0 +1,47 @@
+package simulation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/engine"
+)
+
+const (
+	outageSeparator      = ","
+	outageFieldSeparator = ":"
+)
+
+// parseOutages parses a list of outages in the format: start:length:failure-probability,...
+// For example: "2h:10m:0.9,20h:1h:0.5"
+func parseOutages(spec string) ([]engine.Outage, error) {
+	var res []engine.Outage
+	for _, outageSpec := range strings.Split(spec, outageSeparator) {
+		fields := strings.Split(outageSpec, outageFieldSeparator)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid outage: %s", outageSpec)
+		}
+
+		startSeconds, err := cmd.AsSeconds(fields[0])
+		if err != nil || startSeconds < 0 {
+			return nil, fmt.Errorf("invalid start time in outage: %s", outageSpec)
+		}
+		lengthSeconds, err := cmd.AsSeconds(fields[1])
+		if err != nil || lengthSeconds <= 0 {
+			return nil, fmt.Errorf("invalid length in outage: %s", outageSpec)
+		}
+		failureProbability, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || failureProbability < 0 || failureProbability > 1 {
+			return nil, fmt.Errorf("invalid failure probability in outage: %s", outageSpec)
+		}
+
+		res = append(res, engine.Outage{
+			Start:              float64(cmd.SecondsToMillis(startSeconds)),
+			End:                float64(cmd.SecondsToMillis(startSeconds + lengthSeconds)),
+			FailureProbability: failureProbability,
+		})
+	}
+	return res, nil
+}
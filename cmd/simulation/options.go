@@ -0,0 +1,516 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/engine"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	defaultArgSimulationTime = "24h"
+	defaultSpreadPercent     = "0.02" // 2%
+	defaultObjectCount       = "1000"
+	defaultPeriod            = "5m"
+	defaultJitterStrategy    = "probabilistic"
+	defaultPlacement         = "one-period"
+	defaultProcessingTime    = "const:0ms"
+	defaultBucketBurst       = "100"
+	defaultBackoffBase       = "5ms"
+	defaultBackoffMax        = "1000s"
+	defaultRestartPlacement  = "all-at-once"
+	defaultHashFunction      = "fnv1a"
+	maxObjectCount           = 1000000
+)
+
+// PrintUsage prints the options of the simulation, shared by all commands that run it.
+func PrintUsage() {
+	fmt.Println("   --simulation-time=<time>       Simulated time range (default: " + defaultArgSimulationTime + ")")
+	fmt.Println("   --spread-percent=<float>       Default for both --jitter-probability and --jitter-magnitude (default: " + defaultSpreadPercent + ")")
+	fmt.Println("   --jitter-probability=<float>   Fraction of schedules that are jittered")
+	fmt.Println("   --jitter-magnitude=<float>     How far a jittered schedule moves, as a fraction of the period,")
+	fmt.Println("                                  or of the time of the schedule with the probabilistic strategy")
+	fmt.Println("   --object-count=<uint>          Number of objects (default: " + defaultObjectCount + ")")
+	fmt.Println("   --period=<time>                Base period of every object (default: " + defaultPeriod + ")")
+	fmt.Println("   --classes=<list>               Groups of objects as count:period[:jitter-probability[:jitter-magnitude]],...")
+	fmt.Println("                                  Replaces --object-count, and can't be combined with --period")
+	fmt.Println("   --jitter-strategy=<name>       One of: " + strings.Join(model.JitterStrategyNames, ", ") + " (default: " + defaultJitterStrategy + ")")
+	fmt.Println("   --placement=<name>             First schedule of the objects, one of: " + strings.Join(model.PlacementNames, ", ") + " (default: " + defaultPlacement + ")")
+	fmt.Println("   --hash-function=<name>         Hash of the object ID used by the hash strategy and placement, one of: " + strings.Join(model.HashFunctionNames, ", ") + " (default: " + defaultHashFunction + ")")
+	fmt.Println("   --seed=<uint>                  Seed of the random generator (default: random)")
+	fmt.Println("   --parallelism=<uint>           Number of goroutines simulating the objects (default: number of CPUs)")
+	fmt.Println("                                  The results don't depend on it. Not available with a worker pool, which is simulated serially")
+	fmt.Println("   --workers=<uint>               Number of workers processing due objects (default: unlimited)")
+	fmt.Println("   --processing-time=<dist>       Processing time of a reconciliation: const:<time>, uniform:<time>:<time> or exp:<time>")
+	fmt.Println("   --bucket-qps=<float>           Enables a token bucket rate limiter with the given refill rate")
+	fmt.Println("   --bucket-burst=<uint>          Size of the token bucket (default: " + defaultBucketBurst + ")")
+	fmt.Println("   --backoff-base=<time>          Initial delay of the per-item exponential backoff rate limiter (default: " + defaultBackoffBase + ")")
+	fmt.Println("   --backoff-max=<time>           Maximum delay of the per-item exponential backoff (default: " + defaultBackoffMax + ")")
+	fmt.Println("                                  The backoff only delays the retries of failed reconciliations, so both need failures")
+	fmt.Println("   --failure-probability=<float>  Probability that a reconciliation fails (default: 0)")
+	fmt.Println("   --outages=<list>               Time windows with a different failure probability, as start:length:failure-probability,...")
+	fmt.Println("                                  Failed reconciliations are retried after the per-item exponential backoff")
+	fmt.Println("   --arrivals=<process>           Creates objects during the simulation: poisson:<objects-per-second>,")
+	fmt.Println("                                  bursts:<at>:<count>:<length>,... or file:<path> with one creation time per line")
+	fmt.Println("                                  New objects belong to the first class and are reconciled right when they are created")
+	fmt.Println("   --lifetime=<dist>              Deletes every object after a lifetime drawn from the distribution (default: never)")
+	fmt.Println("   --restarts=<list>              Times when the controller restarts and requeues every object, e.g. 1h,2h30m")
+	fmt.Println("   --restart-mtbf=<time>          Restarts the controller at random, with the given mean time between restarts")
+	fmt.Println("   --restart-placement=<name>     Schedule of the objects after a restart, one of: " + strings.Join(model.PlacementNames, ", ") + " (default: " + defaultRestartPlacement + ")")
+	fmt.Println("   --watch-events=<source>        Reconciles objects when a watched resource changes: poisson:<events-per-object-per-hour>,")
+	fmt.Println("                                  mass:<at>:<fraction-of-objects>:<length>,... or file:<path> with <time>[,<object-id>] per line")
+	fmt.Println("                                  The period of a triggered object starts again from the watch event")
+	fmt.Println("   --events-file=<path>           Stores when every reconciliation was due, started and finished")
+}
+
+// ParseOptions parses the options of the simulation. It exits on invalid values.
+func ParseOptions(args cmd.Arguments) Options {
+	res := Options{}
+
+	argSimulationTime, ok := args.Get("--simulation-time")
+	if !ok {
+		argSimulationTime = defaultArgSimulationTime
+	}
+	simulationTimeSeconds, err := cmd.AsSeconds(argSimulationTime)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --simulation-time: %s\n", argSimulationTime)
+		os.Exit(1)
+	}
+	res.simulationTimeSeconds = simulationTimeSeconds
+
+	argSpreadPercent, ok := args.Get("--spread-percent")
+	if !ok {
+		argSpreadPercent = defaultSpreadPercent
+	}
+	spreadPercent, err := strconv.ParseFloat(argSpreadPercent, 64)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --spread-percent: %s\n", argSpreadPercent)
+		os.Exit(1)
+	}
+	jitterProbability := parseFraction(args, "--jitter-probability", spreadPercent)
+	jitterMagnitude := parseFraction(args, "--jitter-magnitude", spreadPercent)
+
+	argObjectCount, ok := args.Get("--object-count")
+	if !ok {
+		argObjectCount = defaultObjectCount
+	}
+	_, err = strconv.Atoi(argObjectCount)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --object-count: %s\n", argObjectCount)
+		os.Exit(1)
+	}
+
+	argPeriod, okPeriod := args.Get("--period")
+	if !okPeriod {
+		argPeriod = defaultPeriod
+	}
+	periodSeconds, err := cmd.AsSeconds(argPeriod)
+	if err != nil || periodSeconds <= 0 {
+		fmt.Printf("Invalid argument value for --period: %s\n", argPeriod)
+		os.Exit(1)
+	}
+
+	argClasses, ok := args.Get("--classes")
+	if !ok {
+		argClasses = argObjectCount + classFieldSeparator + argPeriod
+	} else if okPeriod {
+		fmt.Println("--period can't be combined with --classes, which sets the period of every class")
+		os.Exit(1)
+	}
+	classes, err := parseObjectClasses(argClasses, jitterProbability, jitterMagnitude)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --classes: %v\n", err)
+		os.Exit(1)
+	}
+	res.classes = classes
+	for _, class := range classes {
+		res.objCount += class.count
+	}
+	if res.objCount <= 100 || res.objCount > maxObjectCount {
+		fmt.Printf("Object count must be between 100 and %d\n", maxObjectCount)
+		os.Exit(1)
+	}
+
+	res.hashFunctionName, ok = args.Get("--hash-function")
+	if !ok {
+		res.hashFunctionName = defaultHashFunction
+	}
+	hashFunction, err := model.NewHashFunction(res.hashFunctionName)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --hash-function: %s\n", res.hashFunctionName)
+		os.Exit(1)
+	}
+
+	jitterStrategyName, ok := args.Get("--jitter-strategy")
+	if !ok {
+		jitterStrategyName = defaultJitterStrategy
+	}
+	jitterStrategy, err := model.NewJitterStrategy(jitterStrategyName, hashFunction)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --jitter-strategy: %s\n", jitterStrategyName)
+		os.Exit(1)
+	}
+	res.jitterStrategyName = jitterStrategyName
+	res.jitterStrategy = jitterStrategy
+
+	res.placementName, ok = args.Get("--placement")
+	if !ok {
+		res.placementName = defaultPlacement
+	}
+	res.placement, err = model.NewPlacement(res.placementName, hashFunction)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --placement: %s\n", res.placementName)
+		os.Exit(1)
+	}
+
+	argSeed, ok := args.Get("--seed")
+	if ok {
+		seed, err := strconv.ParseUint(argSeed, 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --seed: %s\n", argSeed)
+			os.Exit(1)
+		}
+		res.seed = seed
+	} else {
+		res.seed = rand.Uint64()
+	}
+
+	argWorkers, ok := args.Get("--workers")
+	if ok {
+		workers, err := strconv.Atoi(argWorkers)
+		if err != nil || workers < 0 {
+			fmt.Printf("Invalid argument value for --workers: %s\n", argWorkers)
+			os.Exit(1)
+		}
+		res.workers = workers
+	}
+
+	res.argProcessingTime, ok = args.Get("--processing-time")
+	if !ok {
+		res.argProcessingTime = defaultProcessingTime
+	}
+	res.processingTime, err = cmd.AsDistribution(res.argProcessingTime)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --processing-time: %s\n", res.argProcessingTime)
+		os.Exit(1)
+	}
+
+	argBucketQPS, ok := args.Get("--bucket-qps")
+	if ok {
+		res.bucketQPS, err = strconv.ParseFloat(argBucketQPS, 64)
+		if err != nil || res.bucketQPS <= 0 {
+			fmt.Printf("Invalid argument value for --bucket-qps: %s\n", argBucketQPS)
+			os.Exit(1)
+		}
+	}
+
+	argBucketBurst, ok := args.Get("--bucket-burst")
+	if !ok {
+		argBucketBurst = defaultBucketBurst
+	}
+	res.bucketBurst, err = strconv.Atoi(argBucketBurst)
+	if err != nil || res.bucketBurst < 1 {
+		fmt.Printf("Invalid argument value for --bucket-burst: %s\n", argBucketBurst)
+		os.Exit(1)
+	}
+
+	argBackoffBase, okBackoffBase := args.Get("--backoff-base")
+	if !okBackoffBase {
+		argBackoffBase = defaultBackoffBase
+	}
+	res.backoffBaseMillis, err = cmd.AsMillis(argBackoffBase)
+	if err != nil || res.backoffBaseMillis <= 0 {
+		fmt.Printf("Invalid argument value for --backoff-base: %s\n", argBackoffBase)
+		os.Exit(1)
+	}
+
+	argBackoffMax, okBackoffMax := args.Get("--backoff-max")
+	if !okBackoffMax {
+		argBackoffMax = defaultBackoffMax
+	}
+	res.backoffMaxMillis, err = cmd.AsMillis(argBackoffMax)
+	if err != nil || res.backoffMaxMillis <= 0 {
+		fmt.Printf("Invalid argument value for --backoff-max: %s\n", argBackoffMax)
+		os.Exit(1)
+	}
+
+	res.failureProbability = parseFraction(args, "--failure-probability", 0)
+
+	res.argOutages, ok = args.Get("--outages")
+	if ok {
+		res.outages, err = parseOutages(res.argOutages)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --outages: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	// The backoff only delays retries
+	if (okBackoffBase || okBackoffMax) && !res.simulateFailures() {
+		fmt.Println("--backoff-base and --backoff-max need --failure-probability or --outages")
+		os.Exit(1)
+	}
+
+	res.argArrivals, ok = args.Get("--arrivals")
+	if ok {
+		res.arrivals, err = parseArrivals(res.argArrivals)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --arrivals: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	res.argLifetime, ok = args.Get("--lifetime")
+	if ok {
+		res.lifetime, err = cmd.AsDistribution(res.argLifetime)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --lifetime: %s\n", res.argLifetime)
+			os.Exit(1)
+		}
+	}
+
+	res.argRestarts, ok = args.Get("--restarts")
+	if ok {
+		res.restarts, err = parseRestarts(res.argRestarts)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --restarts: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	argRestartMTBF, ok := args.Get("--restart-mtbf")
+	if ok {
+		res.restartMTBFMillis, err = cmd.AsMillis(argRestartMTBF)
+		if err != nil || res.restartMTBFMillis <= 0 {
+			fmt.Printf("Invalid argument value for --restart-mtbf: %s\n", argRestartMTBF)
+			os.Exit(1)
+		}
+	}
+
+	res.restartPlacementName, ok = args.Get("--restart-placement")
+	if !ok {
+		res.restartPlacementName = defaultRestartPlacement
+	}
+	res.restartPlacement, err = model.NewPlacement(res.restartPlacementName, hashFunction)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --restart-placement: %s\n", res.restartPlacementName)
+		os.Exit(1)
+	}
+
+	res.argWatchEvents, ok = args.Get("--watch-events")
+	if ok {
+		res.watchEvents, err = parseWatchEvents(res.argWatchEvents)
+		if err != nil {
+			fmt.Printf("Invalid argument value for --watch-events: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	res.eventsFileName, _ = args.Get("--events-file")
+
+	res.parallelism = runtime.NumCPU()
+	argParallelism, ok := args.Get("--parallelism")
+	if ok {
+		res.parallelism, err = strconv.Atoi(argParallelism)
+		if err != nil || res.parallelism <= 0 {
+			fmt.Printf("Invalid argument value for --parallelism: %s\n", argParallelism)
+			os.Exit(1)
+		}
+		if res.parallelism > 1 && res.useWorkerPool() {
+			fmt.Println("The worker pool is simulated serially, --parallelism must be 1")
+			os.Exit(1)
+		}
+	}
+
+	return res
+}
+
+// parseFraction returns the value of the given argument, which must be in the range 0.0 to 1.0.
+func parseFraction(args cmd.Arguments, name string, defaultValue float64) float64 {
+	argValue, ok := args.Get(name)
+	if !ok {
+		return defaultValue
+	}
+	res, err := strconv.ParseFloat(argValue, 64)
+	if err != nil || res < 0 || res > 1 {
+		fmt.Printf("Invalid argument value for %s: %s\n", name, argValue)
+		os.Exit(1)
+	}
+	return res
+}
+
+// Options are the parameters of a simulation, parsed from the command line.
+type Options struct {
+	simulationTimeSeconds int
+	classes               []objectClass
+	objCount              int
+	jitterStrategyName    string
+	jitterStrategy        model.JitterStrategy
+	placementName         string
+	placement             model.Placement
+	hashFunctionName      string
+	seed                  uint64
+	workers               int
+	argProcessingTime     string
+	processingTime        model.Distribution
+	eventsFileName        string
+	bucketQPS             float64
+	bucketBurst           int
+	backoffBaseMillis     int
+	backoffMaxMillis      int
+	failureProbability    float64
+	argOutages            string
+	outages               []engine.Outage
+	argArrivals           string
+	arrivals              model.ArrivalProcess
+	argLifetime           string
+	lifetime              model.Distribution
+	argRestarts           string
+	restarts              []float64
+	restartMTBFMillis     int
+	restartPlacementName  string
+	restartPlacement      model.Placement
+	argWatchEvents        string
+	watchEvents           model.WatchEventSource
+	parallelism           int
+}
+
+// simulateRestarts tells whether the controller restarts during the simulation.
+func (o Options) simulateRestarts() bool {
+	return len(o.restarts) > 0 || o.restartMTBFMillis > 0
+}
+
+// useHashFunction tells whether the schedules depend on the hash function.
+func (o Options) useHashFunction() bool {
+	return o.jitterStrategyName == "hash" || o.placementName == "hash" || (o.simulateRestarts() && o.restartPlacementName == "hash")
+}
+
+// simulateFailures tells whether reconciliations may fail.
+func (o Options) simulateFailures() bool {
+	return o.failureProbability > 0 || len(o.outages) > 0
+}
+
+// useWorkerPool tells whether reconciliations are processed by a worker pool, instead of just being requeued.
+func (o Options) useWorkerPool() bool {
+	return o.workers > 0 || o.argProcessingTime != defaultProcessingTime || o.eventsFileName != "" || o.bucketQPS > 0 || o.simulateFailures()
+}
+
+// SimulationTimeMillis returns the simulated time range.
+func (o Options) SimulationTimeMillis() int {
+	return cmd.SecondsToMillis(o.simulationTimeSeconds)
+}
+
+// EventsFileName returns the name of the file that stores every reconciliation, or an empty string.
+func (o Options) EventsFileName() string {
+	return o.eventsFileName
+}
+
+// WithEventsFileName returns a copy of the options that stores every reconciliation in the given file.
+func (o Options) WithEventsFileName(fileName string) Options {
+	o.eventsFileName = fileName
+	return o
+}
+
+func (o Options) Seed() uint64 {
+	return o.seed
+}
+
+// WithSeed returns a copy of the options with another seed, e.g. for another run of the same simulation.
+func (o Options) WithSeed(seed uint64) Options {
+	o.seed = seed
+	return o
+}
+
+// PrintSummary prints the options.
+func (o Options) PrintSummary() {
+	fmt.Println("Generating the scheduling of objects over time:")
+	fmt.Printf("   Simulation time: %d:%d:%d [h:m:s]\n", o.simulationTimeSeconds/3600, (o.simulationTimeSeconds%3600)/60, o.simulationTimeSeconds%60)
+	fmt.Printf("   Object count: %d\n", o.objCount)
+	for _, class := range o.classes {
+		fmt.Printf("   Class: %d objects, period: %ds, jitter probability: %.4f, jitter magnitude: %.4f\n", class.count, class.periodMillis/1000, class.jitterProbability, class.jitterMagnitude)
+	}
+	fmt.Printf("   Jitter strategy: %s\n", o.jitterStrategyName)
+	fmt.Printf("   Initial placement: %s\n", o.placementName)
+	if o.useHashFunction() {
+		fmt.Printf("   Hash function: %s\n", o.hashFunctionName)
+	}
+	fmt.Printf("   Seed: %d\n", o.seed)
+	if !o.useWorkerPool() {
+		fmt.Printf("   Parallelism: %d\n", o.parallelism)
+	}
+	if o.useWorkerPool() {
+		fmt.Printf("   Workers: %d (0 means unlimited)\n", o.workers)
+		fmt.Printf("   Processing time: %s\n", o.argProcessingTime)
+	}
+	if o.bucketQPS > 0 {
+		fmt.Printf("   Token bucket: %.2f qps, burst %d\n", o.bucketQPS, o.bucketBurst)
+	}
+	if o.simulateFailures() {
+		fmt.Printf("   Item backoff: base %d ms, max %d ms\n", o.backoffBaseMillis, o.backoffMaxMillis)
+		fmt.Printf("   Failure probability: %.4f\n", o.failureProbability)
+		for _, outage := range o.outages {
+			fmt.Printf("   Outage: %.0fs to %.0fs, failure probability: %.4f\n", outage.Start/1000, outage.End/1000, outage.FailureProbability)
+		}
+	}
+
+	if o.simulateRestarts() {
+		fmt.Printf("   Restart placement: %s\n", o.restartPlacementName)
+	}
+}
+
+// Params returns the options that are stored with the simulation results.
+func (o Options) Params() model.Params {
+	res := model.Params{
+		"jitter-strategy": o.jitterStrategyName,
+		"placement":       o.placementName,
+		"classes":         classesString(o.classes),
+		"seed":            strconv.FormatUint(o.seed, 10),
+		"simulation-time": strconv.Itoa(o.simulationTimeSeconds) + "s",
+	}
+	if o.useWorkerPool() {
+		res["workers"] = strconv.Itoa(o.workers)
+		res["processing-time"] = o.argProcessingTime
+	}
+	if o.bucketQPS > 0 {
+		res.SetFloat("bucket-qps", o.bucketQPS)
+		res["bucket-burst"] = strconv.Itoa(o.bucketBurst)
+	}
+	if o.simulateFailures() {
+		res["backoff-base"] = strconv.Itoa(o.backoffBaseMillis) + "ms"
+		res["backoff-max"] = strconv.Itoa(o.backoffMaxMillis) + "ms"
+		res.SetFloat("failure-probability", o.failureProbability)
+		res["outages"] = o.argOutages
+	}
+	if o.arrivals != nil {
+		res["arrivals"] = o.argArrivals
+	}
+	if o.lifetime != nil {
+		res["lifetime"] = o.argLifetime
+	}
+	if len(o.restarts) > 0 {
+		res["restarts"] = o.argRestarts
+	}
+	if o.restartMTBFMillis > 0 {
+		res["restart-mtbf"] = strconv.Itoa(o.restartMTBFMillis) + "ms"
+	}
+	if o.watchEvents != nil {
+		res["watch-events"] = o.argWatchEvents
+	}
+	if o.simulateRestarts() {
+		res["restart-placement"] = o.restartPlacementName
+	}
+	if o.useHashFunction() {
+		res["hash-function"] = o.hashFunctionName
+	}
+	if len(o.classes) == 1 {
+		res.SetFloat("jitter-probability", o.classes[0].jitterProbability)
+		res.SetFloat("jitter-magnitude", o.classes[0].jitterMagnitude)
+	}
+
+	return res
+}
@@ -0,0 +1,23 @@
+package simulation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+)
+
+const restartSeparator = ","
+
+// parseRestarts parses a list of restart times, e.g. "1h,90m,3h30m".
+func parseRestarts(spec string) ([]float64, error) {
+	var res []float64
+	for _, restartSpec := range strings.Split(spec, restartSeparator) {
+		millis, err := cmd.AsMillis(restartSpec)
+		if err != nil || millis < 0 {
+			return nil, fmt.Errorf("invalid restart time: %s", restartSpec)
+		}
+		res = append(res, float64(millis))
+	}
+	return res, nil
+}
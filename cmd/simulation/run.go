@@ -0,0 +1,173 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/engine"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// Observer creates a component that observes the events of an engine, e.g. to build histograms while the simulation runs.
+// The objects may be simulated by several engines in parallel, so it is called once for every engine.
+type Observer func() engine.Component
+
+// Run creates the objects and simulates their schedules. The observers see every event of the simulation.
+// Without keepHistory, every object only keeps its last schedule, so that the memory doesn't grow with the simulation time.
+func Run(opts Options, keepHistory bool, observers ...Observer) model.ObjSet {
+	var simulationTimeMillis int = cmd.SecondsToMillis(opts.simulationTimeSeconds)
+
+	var objects = model.ObjSet{}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Initializing objects...")
+	rs := model.NewSeededRandomSupport(opts.seed)
+
+	// Every object has its own random generator, so that its schedules don't depend on the other objects
+	newObject := func(class objectClass, born float64) *model.Object {
+		objRS := model.NewStreamRandomSupport(opts.seed, uint64(len(objects)))
+		died := math.Inf(1)
+		if opts.lifetime != nil {
+			died = born + opts.lifetime.Sample(objRS)
+		}
+		obj := model.NewObject(len(objects), born, class.jitterProbability, class.jitterMagnitude).
+			SetPeriod(float64(class.periodMillis)).
+			SetLifetime(born, died).
+			SetRandomSupport(objRS).
+			SetJitterStrategy(opts.jitterStrategy)
+		if !keepHistory {
+			obj.DiscardHistory()
+		}
+		return obj
+	}
+	for _, class := range opts.classes {
+		for i := 0; i < class.count; i++ {
+			obj := newObject(class, 0).PlaceFirstSchedule(0, opts.placement, len(objects), opts.objCount)
+			objects = append(objects, obj)
+		}
+	}
+	if opts.arrivals != nil {
+		arrivals := opts.arrivals.Arrivals(float64(simulationTimeMillis), rs)
+		for _, born := range arrivals {
+			objects = append(objects, newObject(opts.classes[0], born))
+		}
+		fmt.Printf("   Objects created during the simulation: %d\n", len(arrivals))
+	}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Simulating re-schedules...")
+	var restarts []float64
+	if opts.simulateRestarts() {
+		restarts = opts.restarts
+		if opts.restartMTBFMillis > 0 {
+			restarts = append(restarts, model.PoissonArrivals{PerSecond: 1000 / float64(opts.restartMTBFMillis)}.Arrivals(float64(simulationTimeMillis), rs)...)
+		}
+		fmt.Printf("   Controller restarts: %d\n", len(restarts))
+	}
+	var watchEvents []model.WatchEvent
+	if opts.watchEvents != nil {
+		watchEvents = opts.watchEvents.WatchEvents(objects, float64(simulationTimeMillis), rs)
+		fmt.Printf("   Watch events: %d\n", len(watchEvents))
+	}
+
+	// newEngine returns an engine that simulates the shard of the objects, with all external events
+	newEngine := func(shard model.ObjSet) *engine.Engine {
+		sim := engine.New(float64(simulationTimeMillis))
+		if opts.simulateRestarts() {
+			for _, at := range restarts {
+				if at < float64(simulationTimeMillis) {
+					sim.Schedule(engine.Event{Time: at, Kind: engine.Restart})
+				}
+			}
+			sim.AddComponent(engine.NewRestarter(objects, opts.restartPlacement).Shard(shard))
+		}
+		sim.ScheduleWatchEvents(shard, watchEvents)
+		return sim
+	}
+
+	if opts.useWorkerPool() {
+		// All objects share the workers and rate limiters, so they are simulated by a single engine
+		sim := newEngine(objects)
+		for _, observer := range observers {
+			sim.AddComponent(observer())
+		}
+		pool := engine.NewWorkerPool(opts.workers, opts.processingTime, rs)
+		var bucket *engine.TokenBucket
+		if opts.bucketQPS > 0 {
+			bucket = engine.NewTokenBucket(opts.bucketQPS, opts.bucketBurst)
+		}
+		var backoff *engine.ItemBackoff
+		if opts.simulateFailures() {
+			backoff = engine.NewItemBackoff(float64(opts.backoffBaseMillis), float64(opts.backoffMaxMillis))
+			pool.SetFailures(engine.NewFailureModel(opts.failureProbability, opts.outages, rs))
+		}
+		pool.SetRateLimiters(bucket, backoff)
+		if opts.eventsFileName != "" {
+			eventsFile, err := os.Create(opts.eventsFileName)
+			if err != nil {
+				fmt.Printf("Error creating file: %v\n", err)
+				os.Exit(1)
+			}
+			defer func() {
+				err := eventsFile.Close()
+				if err != nil {
+					fmt.Printf("Error closing events file: %v\n", err)
+				}
+			}()
+			reconcileWriter := model.NewReconcileWriter(eventsFile)
+			defer func() {
+				err := reconcileWriter.Flush()
+				if err != nil {
+					fmt.Printf("Error writing events file: %v\n", err)
+				}
+			}()
+			pool.OnReconcile(func(r model.Reconcile) {
+				err := reconcileWriter.Write(r)
+				if err != nil {
+					fmt.Printf("Error writing events file: %v\n", err)
+					os.Exit(1)
+				}
+			})
+		}
+		sim.AddComponent(pool)
+		sim.ScheduleObjects(objects)
+		sim.Run()
+	} else {
+		// Objects don't interact, and every object has its own random generator, so the shards can be simulated in parallel
+		// The observers are created before any engine runs, so that they don't need to be safe for concurrent use
+		var sims []*engine.Engine
+		for _, shard := range splitObjects(objects, opts.parallelism) {
+			sim := newEngine(shard)
+			sim.AddComponent(engine.PeriodicRequeue{})
+			for _, observer := range observers {
+				sim.AddComponent(observer())
+			}
+			sim.ScheduleObjects(shard)
+			sims = append(sims, sim)
+		}
+		var wg sync.WaitGroup
+		for _, sim := range sims {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sim.Run()
+			}()
+		}
+		wg.Wait()
+	}
+
+	return objects
+}
+
+// splitObjects splits the objects into at most count shards of similar size, keeping their order.
+func splitObjects(objects model.ObjSet, count int) []model.ObjSet {
+	var res []model.ObjSet
+	size := (len(objects) + count - 1) / count
+	for start := 0; start < len(objects); start += size {
+		res = append(res, objects[start:min(start+size, len(objects))])
+	}
+	return res
+}
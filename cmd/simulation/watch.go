@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	massUpdateSeparator      = ","
+	massUpdateFieldSeparator = ":"
+	watchEventFieldSeparator = ","
+)
+
+// parseWatchEvents parses a watch event source in one of the formats:
+// poisson:<events-per-object-per-hour>, mass:<at>:<fraction>:<length>,... or file:<path>.
+// Every line of the file is either <time>,<object-id> or just <time>, which triggers all objects, e.g. "90s,17".
+func parseWatchEvents(spec string) (model.WatchEventSource, error) {
+	kind, value, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "poisson":
+		perHour, err := strconv.ParseFloat(value, 64)
+		if err != nil || perHour <= 0 {
+			return nil, fmt.Errorf("invalid rate of poisson watch events: %s", value)
+		}
+		return model.PoissonWatchEvents{PerHour: perHour}, nil
+	case "mass":
+		return parseMassUpdates(value)
+	case "file":
+		return readWatchEvents(value)
+	}
+	return nil, fmt.Errorf("invalid watch event source: %s", spec)
+}
+
+func parseMassUpdates(spec string) (model.WatchEventSource, error) {
+	var res model.MassUpdates
+	for _, updateSpec := range strings.Split(spec, massUpdateSeparator) {
+		fields := strings.Split(updateSpec, massUpdateFieldSeparator)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid mass update: %s", updateSpec)
+		}
+		atMillis, err := cmd.AsMillis(fields[0])
+		if err != nil || atMillis < 0 {
+			return nil, fmt.Errorf("invalid time of mass update: %s", updateSpec)
+		}
+		fraction, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || fraction <= 0 || fraction > 1 {
+			return nil, fmt.Errorf("invalid fraction of objects in mass update: %s", updateSpec)
+		}
+		lengthMillis, err := cmd.AsMillis(fields[2])
+		if err != nil || lengthMillis < 0 {
+			return nil, fmt.Errorf("invalid length of mass update: %s", updateSpec)
+		}
+		res.Updates = append(res.Updates, model.MassUpdate{
+			At:       float64(atMillis),
+			Fraction: fraction,
+			Length:   float64(lengthMillis),
+		})
+	}
+	return res, nil
+}
+
+func readWatchEvents(path string) (model.WatchEventSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var res model.ReplayedWatchEvents
+	bf := bufio.NewScanner(file)
+	for bf.Scan() {
+		line := strings.TrimSpace(bf.Text())
+		if line == "" {
+			continue
+		}
+		timeField, idField, hasID := strings.Cut(line, watchEventFieldSeparator)
+		millis, err := cmd.AsMillis(timeField)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watch event time in %s: %s", path, line)
+		}
+		ev := model.WatchEvent{Time: float64(millis), ObjectID: model.AllObjects}
+		if hasID {
+			ev.ObjectID, err = strconv.Atoi(strings.TrimSpace(idField))
+			if err != nil || ev.ObjectID < 0 {
+				return nil, fmt.Errorf("invalid watch event object ID in %s: %s", path, line)
+			}
+		}
+		res.Events = append(res.Events, ev)
+	}
+	return res, bf.Err()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd/simulation"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/engine"
+)
+
+const (
+	defaultArgGraphStartTime = "24h"
+	defaultArgGraphLength    = "60m"
+	defaultArgImageFileName  = "out.png"
+	bucketCount              = 1000
+)
+
+func main() {
+
+	opts := parseCLIArguments(os.Args)
+
+	for _, imageFileName := range opts.imageFileNames() {
+		fileAlreadyExists, err := cmd.FileExists(imageFileName)
+		if err != nil {
+			fmt.Println("Error checking if image file exists:", err)
+			os.Exit(1)
+		}
+		if fileAlreadyExists && !opts.overwriteImageFile {
+			fmt.Printf("Image file already exists: %s\n", imageFileName)
+			os.Exit(1)
+		}
+	}
+
+	var graphStartTimeMillis int = cmd.SecondsToMillis(opts.graphStartTimeSeconds)
+	var graphLengthMillis int = cmd.SecondsToMillis(opts.graphLengthSeconds)
+	if graphLengthMillis%bucketCount != 0 {
+		panic("graphLengthMillis must be divisible by bucketCount")
+	}
+
+	fmt.Println("================================================================================")
+	opts.PrintSummary()
+
+	// Every engine fills its own histograms, which are merged once the simulation is finished
+	var graphs, overviews []*engine.ScheduleHistogram
+	objects := simulation.Run(opts.Options, false,
+		func() engine.Component {
+			graph := engine.NewScheduleHistogram(graphStartTimeMillis, graphLengthMillis/bucketCount, bucketCount)
+			graphs = append(graphs, graph)
+			return graph
+		},
+		func() engine.Component {
+			overview := engine.NewScheduleHistogram(0, opts.SimulationTimeMillis()/bucketCount, bucketCount)
+			overviews = append(overviews, overview)
+			return overview
+		})
+	graph, overview := graphs[0], overviews[0]
+	for i := 1; i < len(graphs); i++ {
+		graph.Merge(graphs[i])
+		overview.Merge(overviews[i])
+	}
+
+	expectedSchedules := objects.ExpectedSchedules(float64(graphStartTimeMillis), float64(graphLengthMillis)) // Assuming perfectly uniform distribution
+	fmt.Println("   Expected schedules:", int(expectedSchedules))
+	fmt.Println("   Total schedules:", graph.Schedules().TotalCount())
+	fmt.Println("   Retries:", graph.Retries().TotalCount())
+
+	fmt.Println("================================================================================")
+	fmt.Println("Drawing histograms")
+	draw.DrawWithHighlight(graph.Schedules(), graph.Retries(), opts.argGraphStartTime, opts.argGraphLength, opts.imageFileName)
+	draw.DrawWithHighlight(overview.Schedules(), overview.Retries(), "0s", fmt.Sprintf("%ds", opts.SimulationTimeMillis()/1000), opts.overviewImageFileName())
+
+	fmt.Println("================================================================================")
+	fmt.Println("Done")
+}
+
+func parseCLIArguments(osArgs []string) options {
+	res := options{}
+
+	if len(osArgs) < 2 {
+		fmt.Println("Runs the simulation and plots the histogram of the schedules right away, without storing them.")
+		fmt.Println("The memory used doesn't grow with the simulation time. The whole simulation is also drawn to <image-file>-overview.png")
+		fmt.Println("Usage: go run . [--image-file=<path>] [--overwrite-image-file] --graph-start-time=<time> --graph-length=<time> [options]")
+		fmt.Println("Options:")
+		simulation.PrintUsage()
+		fmt.Println("Example: go run . --image-file=out.png --graph-start-time=4m --graph-length=4h --simulation-time=36h --object-count=100000")
+		os.Exit(1)
+	}
+
+	args := cmd.Arguments{}
+	for i := 1; i < len(osArgs); i++ {
+		args.Add(osArgs[i])
+	}
+
+	argImageFileName, ok := args.Get("--image-file")
+	if !ok {
+		argImageFileName = defaultArgImageFileName
+	}
+	res.imageFileName = argImageFileName
+
+	_, ok = args.Get("--overwrite-image-file")
+	res.overwriteImageFile = ok
+
+	argGraphStartTime, ok := args.Get("--graph-start-time")
+	if !ok {
+		argGraphStartTime = defaultArgGraphStartTime
+	}
+	res.argGraphStartTime = argGraphStartTime
+	graphStartTimeSeconds, err := cmd.AsSeconds(argGraphStartTime)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --graph-start-time: %s\n", argGraphStartTime)
+		os.Exit(1)
+	}
+	res.graphStartTimeSeconds = graphStartTimeSeconds
+
+	argGraphLength, ok := args.Get("--graph-length")
+	if !ok {
+		argGraphLength = defaultArgGraphLength
+	}
+	res.argGraphLength = argGraphLength
+	graphLengthSeconds, err := cmd.AsSeconds(argGraphLength)
+	if err != nil || graphLengthSeconds <= 0 {
+		fmt.Printf("Invalid argument value for --graph-length: %s\n", argGraphLength)
+		os.Exit(1)
+	}
+	res.graphLengthSeconds = graphLengthSeconds
+
+	res.Options = simulation.ParseOptions(args)
+
+	return res
+}
+
+type options struct {
+	simulation.Options
+	imageFileName         string
+	overwriteImageFile    bool
+	argGraphStartTime     string
+	graphStartTimeSeconds int
+	argGraphLength        string
+	graphLengthSeconds    int
+}
+
+// imageFileNames returns the names of all images drawn with these options.
+func (o options) imageFileNames() []string {
+	return []string{o.imageFileName, o.overviewImageFileName()}
+}
+
+func (o options) overviewImageFileName() string {
+	return cmd.WithSuffix(o.imageFileName, "-overview")
+}
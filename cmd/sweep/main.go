@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd/simulation"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/draw"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/engine"
+)
+
+const (
+	defaultSpreadPercent    = "0.02"
+	defaultObjectCount      = "1000"
+	defaultPeriod           = "5m"
+	defaultBucketWidth      = "10s"
+	defaultFlattenThreshold = "1.5"
+	defaultChartFileName    = "sweep.png"
+)
+
+// sweptParams are the names of the swept parameters, in the order of the columns of the results.
+var sweptParams = []string{"spread-percent", "object-count", "period"}
+
+// result holds the metrics of one combination of the swept parameters.
+type result struct {
+	values              []string // Values of the swept parameters
+	peakToMean          float64
+	timeToFlattenMillis float64 // -1 if the load never flattens
+}
+
+func main() {
+
+	opts := parseCLIArguments(os.Args)
+
+	for _, fileName := range opts.outputFileNames() {
+		fileExists, err := cmd.FileExists(fileName)
+		if err != nil {
+			fmt.Println("Error checking if output file exists:", err)
+			os.Exit(1)
+		}
+		if fileExists && !opts.overwriteOutputFiles {
+			fmt.Printf("File %s already exists. Please remove it or choose another file name.\n", fileName)
+			os.Exit(1)
+		}
+	}
+
+	var results []result
+	for _, values := range opts.combinations() {
+		args := opts.args
+		for i, name := range sweptParams {
+			args = args.With(name, values[i])
+		}
+
+		fmt.Println("================================================================================")
+		fmt.Printf("Sweeping %s\n", combinationString(values))
+		simOpts := simulation.ParseOptions(args)
+		simOpts.PrintSummary()
+		results = append(results, opts.measure(simOpts, values))
+	}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Results:")
+	fmt.Printf("   %-16s%-16s%-16s%-16s%s\n", "spread-percent", "object-count", "period", "peak/mean", "time-to-flatten")
+	for _, r := range results {
+		fmt.Printf("   %-16s%-16s%-16s%-16.3f%s\n", r.values[0], r.values[1], r.values[2], r.peakToMean, millisString(r.timeToFlattenMillis))
+	}
+
+	fmt.Println("================================================================================")
+	fmt.Println("Writing results to a file...")
+	writeResults(opts.resultsFileName, results)
+
+	fmt.Println("================================================================================")
+	fmt.Println("Drawing charts")
+	opts.drawCharts(results)
+
+	fmt.Println("================================================================================")
+	fmt.Println("Done")
+}
+
+// measure runs the simulation and computes the metrics from the histogram of all schedules.
+func (o options) measure(simOpts simulation.Options, values []string) result {
+	bucketCount := simOpts.SimulationTimeMillis() / o.bucketWidthMillis
+	var hists []*engine.ScheduleHistogram
+	simulation.Run(simOpts, false, func() engine.Component {
+		hist := engine.NewScheduleHistogram(0, o.bucketWidthMillis, bucketCount)
+		hists = append(hists, hist)
+		return hist
+	})
+	for i := 1; i < len(hists); i++ {
+		hists[0].Merge(hists[i])
+	}
+	counts := hists[0].Schedules().Data()
+
+	// The load is flat if it is flat over every window of one period
+	periodSeconds, _ := cmd.AsSeconds(values[2])
+	window := max(1, cmd.SecondsToMillis(periodSeconds)/o.bucketWidthMillis)
+
+	measureFrom := o.measureFromMillis
+	if measureFrom < 0 {
+		measureFrom = simOpts.SimulationTimeMillis() / 2
+	}
+	res := result{
+		values:              values,
+		peakToMean:          analysis.PeakToMean(counts[min(measureFrom/o.bucketWidthMillis, len(counts)):]),
+		timeToFlattenMillis: -1,
+	}
+	if idx := analysis.TimeToFlatten(counts, window, o.flattenThreshold); idx >= 0 {
+		res.timeToFlattenMillis = float64(idx * o.bucketWidthMillis)
+	}
+	fmt.Printf("   Peak/mean: %.3f, time to flatten: %s\n", res.peakToMean, millisString(res.timeToFlattenMillis))
+	return res
+}
+
+func writeResults(fileName string, results []result) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		fmt.Printf("Error creating file: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			fmt.Printf("Error closing results file: %v\n", err)
+		}
+	}()
+
+	_, err = fmt.Fprintln(file, strings.Join(sweptParams, ",")+",peak-mean,time-to-flatten-seconds")
+	if err != nil {
+		fmt.Printf("Error writing results file: %v\n", err)
+		os.Exit(1)
+	}
+	for _, r := range results {
+		timeToFlatten := ""
+		if r.timeToFlattenMillis >= 0 {
+			timeToFlatten = strconv.FormatFloat(r.timeToFlattenMillis/1000, 'f', -1, 64)
+		}
+		_, err = fmt.Fprintf(file, "%s,%s,%s\n", strings.Join(r.values, ","), strconv.FormatFloat(r.peakToMean, 'f', -1, 64), timeToFlatten)
+		if err != nil {
+			fmt.Printf("Error writing results file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// drawCharts draws every metric against the first swept parameter with more than one value,
+// with one line for every combination of the other parameters.
+func (o options) drawCharts(results []result) {
+	xParam := 0
+	for i := range sweptParams {
+		if len(o.values[i]) > 1 {
+			xParam = i
+			break
+		}
+	}
+	xValues := o.values[xParam]
+
+	var labels []string
+	peakToMean := map[string][]float64{}
+	timeToFlatten := map[string][]float64{}
+	for _, r := range results {
+		var others []string
+		for i, val := range r.values {
+			if i != xParam && len(o.values[i]) > 1 {
+				others = append(others, val)
+			}
+		}
+		label := strings.Join(others, " ")
+		if _, ok := peakToMean[label]; !ok {
+			labels = append(labels, label)
+		}
+		peakToMean[label] = append(peakToMean[label], r.peakToMean)
+		// Never flattening is drawn as the whole simulation time
+		flatten := r.timeToFlattenMillis
+		if flatten < 0 {
+			flatten = float64(o.simulationTimeMillis)
+		}
+		timeToFlatten[label] = append(timeToFlatten[label], flatten/1000)
+	}
+
+	var peakLines, flattenLines []draw.Line
+	for i, label := range labels {
+		r, g, b := draw.LineColor(i)
+		peakLines = append(peakLines, draw.Line{Label: label, Values: peakToMean[label], R: r, G: g, B: b})
+		flattenLines = append(flattenLines, draw.Line{Label: label, Values: timeToFlatten[label], R: r, G: g, B: b})
+	}
+	first, last := sweptParams[xParam]+"="+xValues[0], xValues[len(xValues)-1]
+	draw.DrawLinesOver(peakLines, "x", first, last, o.peakToMeanChartFileName())
+	draw.DrawLinesOver(flattenLines, "s", first, last, o.timeToFlattenChartFileName())
+}
+
+func combinationString(values []string) string {
+	parts := make([]string, len(values))
+	for i, val := range values {
+		parts[i] = sweptParams[i] + "=" + val
+	}
+	return strings.Join(parts, ", ")
+}
+
+func millisString(millis float64) string {
+	if millis < 0 {
+		return "never"
+	}
+	return fmt.Sprintf("%.0fs", millis/1000)
+}
+
+func parseCLIArguments(osArgs []string) options {
+	res := options{}
+
+	if len(osArgs) < 2 {
+		fmt.Println("Runs the simulation for every combination of the swept parameters, and reports how flat the load is.")
+		fmt.Println("Metrics: the peak to mean ratio of the schedules per bucket, from --measure-from to the end of the simulation,")
+		fmt.Println("and the time from which the peak to mean ratio of every window of one period stays below --flatten-threshold.")
+		fmt.Println("Usage: go run . --results-file=<path> [--chart-file=<path>] [options]")
+		fmt.Println("Swept parameters, as a list v1,v2,... or a range from:to:steps:")
+		fmt.Println("   --spread-percent=<sweep>       e.g. 0.005:0.2:20 (default: " + defaultSpreadPercent + ")")
+		fmt.Println("   --object-count=<sweep>         e.g. 1000,10000 (default: " + defaultObjectCount + ")")
+		fmt.Println("   --period=<list>                e.g. 1m,5m,1h, only as a list (default: " + defaultPeriod + ")")
+		fmt.Println("Sweep options:")
+		fmt.Println("   --results-file=<path>          Stores the metrics of every combination as CSV")
+		fmt.Println("   --chart-file=<path>            Every metric is drawn against the first swept parameter with several values,")
+		fmt.Println("                                  to <chart-file>-peak-mean.png and <chart-file>-time-to-flatten.png (default: " + defaultChartFileName + ")")
+		fmt.Println("   --bucket-width=<time>          Width of the histogram buckets the metrics are computed from (default: " + defaultBucketWidth + ")")
+		fmt.Println("   --measure-from=<time>          Start of the peak to mean measurement (default: half of the simulation time)")
+		fmt.Println("   --flatten-threshold=<float>    Peak to mean ratio up to which the load is flat (default: " + defaultFlattenThreshold + ")")
+		fmt.Println("   --overwrite-output-files       Overwrite existing output files")
+		fmt.Println("Simulation options, the same for every combination:")
+		simulation.PrintUsage()
+		fmt.Println("Example: go run . --results-file=sweep.csv --spread-percent=0.005:0.2:20 --object-count=1000,10000 --simulation-time=12h")
+		os.Exit(1)
+	}
+
+	for i := 1; i < len(osArgs); i++ {
+		res.args.Add(osArgs[i])
+	}
+	args := res.args
+
+	resultsFileName, ok := args.Get("--results-file")
+	if !ok {
+		fmt.Println("Missing argument --results-file")
+		os.Exit(1)
+	}
+	res.resultsFileName = resultsFileName
+
+	res.chartFileName, ok = args.Get("--chart-file")
+	if !ok {
+		res.chartFileName = defaultChartFileName
+	}
+
+	_, ok = args.Get("--overwrite-output-files")
+	res.overwriteOutputFiles = ok
+
+	argBucketWidth, ok := args.Get("--bucket-width")
+	if !ok {
+		argBucketWidth = defaultBucketWidth
+	}
+	var err error
+	res.bucketWidthMillis, err = cmd.AsMillis(argBucketWidth)
+	if err != nil || res.bucketWidthMillis <= 0 {
+		fmt.Printf("Invalid argument value for --bucket-width: %s\n", argBucketWidth)
+		os.Exit(1)
+	}
+
+	res.measureFromMillis = -1
+	argMeasureFrom, ok := args.Get("--measure-from")
+	if ok {
+		res.measureFromMillis, err = cmd.AsMillis(argMeasureFrom)
+		if err != nil || res.measureFromMillis < 0 {
+			fmt.Printf("Invalid argument value for --measure-from: %s\n", argMeasureFrom)
+			os.Exit(1)
+		}
+	}
+
+	argFlattenThreshold, ok := args.Get("--flatten-threshold")
+	if !ok {
+		argFlattenThreshold = defaultFlattenThreshold
+	}
+	res.flattenThreshold, err = strconv.ParseFloat(argFlattenThreshold, 64)
+	if err != nil || res.flattenThreshold < 1 {
+		fmt.Printf("Invalid argument value for --flatten-threshold: %s\n", argFlattenThreshold)
+		os.Exit(1)
+	}
+
+	defaults := []string{defaultSpreadPercent, defaultObjectCount, defaultPeriod}
+	for i, name := range sweptParams {
+		spec, ok := args.Get(name)
+		if !ok {
+			spec = defaults[i]
+		}
+		var values []string
+		if name == "period" {
+			values = strings.Split(spec, valueSeparator)
+		} else {
+			values, err = parseSweepValues(spec, name == "object-count")
+		}
+		if err != nil {
+			fmt.Printf("Invalid argument value for --%s: %v\n", name, err)
+			os.Exit(1)
+		}
+		res.values = append(res.values, values)
+	}
+
+	// Every combination is simulated with the same seed, so that only the swept parameters differ
+	if _, ok := args.Get("--seed"); !ok {
+		res.args = res.args.With("--seed", strconv.FormatUint(rand.Uint64(), 10))
+	}
+
+	// All combinations share the simulation time, parse it the same way as the simulation
+	res.simulationTimeMillis = simulation.ParseOptions(res.args.With(sweptParams[0], res.values[0][0]).
+		With(sweptParams[1], res.values[1][0]).With(sweptParams[2], res.values[2][0])).SimulationTimeMillis()
+	if res.simulationTimeMillis%res.bucketWidthMillis != 0 {
+		fmt.Println("The simulation time must be a multiple of --bucket-width")
+		os.Exit(1)
+	}
+
+	return res
+}
+
+type options struct {
+	args                 cmd.Arguments
+	values               [][]string // Values of every swept parameter
+	resultsFileName      string
+	chartFileName        string
+	overwriteOutputFiles bool
+	bucketWidthMillis    int
+	measureFromMillis    int // -1 means half of the simulation time
+	flattenThreshold     float64
+	simulationTimeMillis int
+}
+
+// combinations returns all combinations of the values of the swept parameters, the last parameter changing fastest.
+func (o options) combinations() [][]string {
+	res := [][]string{{}}
+	for _, values := range o.values {
+		var next [][]string
+		for _, combination := range res {
+			for _, val := range values {
+				next = append(next, append(append([]string(nil), combination...), val))
+			}
+		}
+		res = next
+	}
+	return res
+}
+
+// outputFileNames returns the names of all files written with these options.
+func (o options) outputFileNames() []string {
+	return []string{o.resultsFileName, o.peakToMeanChartFileName(), o.timeToFlattenChartFileName()}
+}
+
+func (o options) peakToMeanChartFileName() string {
+	return cmd.WithSuffix(o.chartFileName, "-peak-mean")
+}
+
+func (o options) timeToFlattenChartFileName() string {
+	return cmd.WithSuffix(o.chartFileName, "-time-to-flatten")
+}
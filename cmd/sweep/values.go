@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	valueSeparator      = ","
+	rangeFieldSeparator = ":"
+	rangePrecision      = 1e9 // Range values are rounded, so that they are printed without floating point noise
+)
+
+// parseSweepValues parses the values of a swept parameter, either a list "v1,v2,..." or a range "from:to:steps" of evenly spaced numbers,
+// including both ends. The values are returned as strings, to be passed to the simulation options.
+// With integers, range values are rounded to integers.
+func parseSweepValues(spec string, integers bool) ([]string, error) {
+	fields := strings.Split(spec, rangeFieldSeparator)
+	if len(fields) != 3 {
+		return strings.Split(spec, valueSeparator), nil
+	}
+
+	from, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of range: %s", spec)
+	}
+	to, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of range: %s", spec)
+	}
+	steps, err := strconv.Atoi(fields[2])
+	if err != nil || steps < 2 {
+		return nil, fmt.Errorf("invalid number of steps, at least 2 are needed: %s", spec)
+	}
+
+	var res []string
+	for i := 0; i < steps; i++ {
+		val := from + (to-from)*float64(i)/float64(steps-1)
+		if integers {
+			res = append(res, strconv.Itoa(int(math.Round(val))))
+		} else {
+			res = append(res, strconv.FormatFloat(math.Round(val*rangePrecision)/rangePrecision, 'f', -1, 64))
+		}
+	}
+	return res, nil
+}
@@ -38,3 +38,16 @@ func SecondsToMillis(seconds int) int {
 func MinutesToMillis(minutes int) int {
 	return minutes * 60 * 1000
 }
+
+// AsMillis works like AsSeconds, but returns milliseconds and also accepts the "ms" suffix.
+func AsMillis(userTime string) (int, error) {
+	value := strings.TrimSpace(userTime)
+	if strings.HasSuffix(value, "ms") {
+		return strconv.Atoi(value[:len(value)-2])
+	}
+	seconds, err := AsSeconds(value)
+	if err != nil {
+		return -1, err
+	}
+	return SecondsToMillis(seconds), nil
+}
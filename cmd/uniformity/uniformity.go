@@ -0,0 +1,161 @@
+// Package uniformity measures when the load of a simulation becomes flat, shared by the commands that read or produce all schedules.
+package uniformity
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/cmd"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	defaultMetric      = "cv"
+	defaultBucketWidth = "10s"
+)
+
+// defaultThresholds are a little above the values of a random, flat load of a thousand objects.
+var defaultThresholds = map[string]float64{"cv": 0.5, "kl": 0.05, "peak-mean": 1.5}
+
+// PrintUsage prints the options parsed by ParseOptions.
+func PrintUsage() {
+	fmt.Println("   --uniformity-metric=<name>     How far the load of a window is from flat, one of: " + strings.Join(analysis.UniformityMetricNames, ", ") + " (default: " + defaultMetric + ")")
+	fmt.Println("   --uniformity-threshold=<float> The load is flat once the metric of every later window is at most this (default: cv 0.5, kl 0.05, peak-mean 1.5)")
+	fmt.Println("   --uniformity-window=<time>     Length of the sliding windows (default: the longest period of the objects)")
+	fmt.Println("   --uniformity-bucket-width=<time> Width of the buckets the windows consist of (default: " + defaultBucketWidth + ")")
+	fmt.Println("   --uniformity-file=<path>       Stores the time to uniformity and the metric of every window in a JSON file")
+}
+
+// ParseOptions parses the options that configure the time to uniformity. It exits if an option is invalid.
+func ParseOptions(args cmd.Arguments) Options {
+	res := Options{}
+
+	metricName, ok := args.Get("--uniformity-metric")
+	if !ok {
+		metricName = defaultMetric
+	}
+	metric, err := analysis.NewUniformityMetric(metricName)
+	if err != nil {
+		fmt.Printf("Invalid argument value for --uniformity-metric: %s\n", metricName)
+		os.Exit(1)
+	}
+	res.metricName = metricName
+	res.metric = metric
+
+	res.threshold = defaultThresholds[metricName]
+	argThreshold, ok := args.Get("--uniformity-threshold")
+	if ok {
+		res.threshold, err = strconv.ParseFloat(argThreshold, 64)
+		if err != nil || res.threshold < 0 {
+			fmt.Printf("Invalid argument value for --uniformity-threshold: %s\n", argThreshold)
+			os.Exit(1)
+		}
+	}
+
+	argWindow, ok := args.Get("--uniformity-window")
+	if ok {
+		res.windowMillis, err = cmd.AsMillis(argWindow)
+		if err != nil || res.windowMillis <= 0 {
+			fmt.Printf("Invalid argument value for --uniformity-window: %s\n", argWindow)
+			os.Exit(1)
+		}
+	}
+
+	argBucketWidth, ok := args.Get("--uniformity-bucket-width")
+	if !ok {
+		argBucketWidth = defaultBucketWidth
+	}
+	res.bucketWidthMillis, err = cmd.AsMillis(argBucketWidth)
+	if err != nil || res.bucketWidthMillis <= 0 {
+		fmt.Printf("Invalid argument value for --uniformity-bucket-width: %s\n", argBucketWidth)
+		os.Exit(1)
+	}
+
+	res.fileName, _ = args.Get("--uniformity-file")
+
+	return res
+}
+
+// Options configure how the time to uniformity is measured.
+type Options struct {
+	metricName        string
+	metric            analysis.UniformityMetric
+	threshold         float64
+	windowMillis      int // 0 means the longest period of the objects
+	bucketWidthMillis int
+	fileName          string
+}
+
+// FileName returns the JSON file to store the results in, or an empty string.
+func (o Options) FileName() string {
+	return o.fileName
+}
+
+// Result is the time to uniformity of a simulation, and the metric of every sliding window it was derived from.
+type Result struct {
+	Source                  string     `json:"source"`
+	Metric                  string     `json:"metric"`
+	Threshold               float64    `json:"threshold"`
+	BucketWidthSeconds      float64    `json:"bucketWidthSeconds"`
+	WindowSeconds           float64    `json:"windowSeconds"`
+	TimeToUniformitySeconds *float64   `json:"timeToUniformitySeconds"` // Nil if the load never becomes flat
+	Values                  []*float64 `json:"values"`                  // The metric of the window starting at every bucket, nil if there were no schedules in it
+}
+
+// Measure computes the time to uniformity of all schedules of the objects, from the start of the simulation until the given time.
+// The source names the simulation in the results, e.g. by its CSV file.
+func (o Options) Measure(source string, objects model.ObjSet, untilMillis int) Result {
+	windowMillis := o.windowMillis
+	if windowMillis == 0 {
+		for _, obj := range objects {
+			windowMillis = max(windowMillis, int(obj.Period()))
+		}
+	}
+	window := max(1, int(math.Round(float64(windowMillis)/float64(o.bucketWidthMillis))))
+
+	hist := analysis.ScheduleHistogram(objects, 0, o.bucketWidthMillis, max(1, untilMillis/o.bucketWidthMillis))
+
+	values := analysis.SlidingWindows(hist.Data(), window, o.metric)
+	res := Result{
+		Source:             source,
+		Metric:             o.metricName,
+		Threshold:          o.threshold,
+		BucketWidthSeconds: float64(o.bucketWidthMillis) / 1000,
+		WindowSeconds:      float64(window*o.bucketWidthMillis) / 1000,
+		Values:             make([]*float64, len(values)),
+	}
+	for i, val := range values {
+		if !math.IsNaN(val) {
+			res.Values[i] = &val
+		}
+	}
+	if idx := analysis.TimeToUniformity(values, o.threshold); idx >= 0 {
+		seconds := float64(idx*o.bucketWidthMillis) / 1000
+		res.TimeToUniformitySeconds = &seconds
+	}
+	return res
+}
+
+// Print prints the time to uniformity as part of a summary.
+func (r Result) Print() {
+	timeToUniformity := "never"
+	if r.TimeToUniformitySeconds != nil {
+		timeToUniformity = (time.Duration(*r.TimeToUniformitySeconds) * time.Second).String()
+	}
+	fmt.Printf("   Time to uniformity (%s <= %s in every later window of %.0fs): %s\n", r.Metric, strconv.FormatFloat(r.Threshold, 'f', -1, 64), r.WindowSeconds, timeToUniformity)
+}
+
+// WriteFile stores the results in a JSON file.
+func WriteFile(fileName string, results []Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, append(data, '\n'), 0644)
+}
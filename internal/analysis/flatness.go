@@ -0,0 +1,38 @@
+package analysis
+
+import (
+	"math"
+)
+
+// Mean returns the average number of data points per bucket. It returns NaN for an empty slice.
+func Mean(counts []int) float64 {
+	if len(counts) == 0 {
+		return math.NaN()
+	}
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return float64(total) / float64(len(counts))
+}
+
+// PeakToMean returns the ratio of the highest bucket to the mean of all buckets. A perfectly flat load has a ratio of 1.
+// It returns NaN if there are no data points.
+func PeakToMean(counts []int) float64 {
+	mean := Mean(counts)
+	if math.IsNaN(mean) || mean == 0 {
+		return math.NaN()
+	}
+	peak := 0
+	for _, count := range counts {
+		peak = max(peak, count)
+	}
+	return float64(peak) / mean
+}
+
+// TimeToFlatten returns the index of the first bucket from which the load stays flat: the peak to mean ratio
+// of every window of the given number of buckets that starts there or later is at most the threshold.
+// It returns -1 if the load never flattens.
+func TimeToFlatten(counts []int, window int, threshold float64) int {
+	return TimeToUniformity(SlidingWindows(counts, window, PeakToMean), threshold)
+}
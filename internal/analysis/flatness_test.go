@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeakToMean(t *testing.T) {
+	assert.True(t, math.IsNaN(PeakToMean([]int{})))
+	assert.True(t, math.IsNaN(PeakToMean([]int{0, 0})))
+	assert.Equal(t, 1.0, PeakToMean([]int{5, 5, 5}))
+	assert.Equal(t, 3.0, PeakToMean([]int{0, 6, 0}))
+}
+
+func TestTimeToFlatten(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   []int
+		expected int
+	}{
+		{"flat from the start", []int{4, 4, 4, 4, 4}, 0},
+		{"herd at the start", []int{0, 20, 0, 5, 4, 5, 4}, 3},
+		{"herd again later", []int{4, 4, 4, 4, 20, 0}, -1},
+		{"empty", []int{0, 0, 0}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, TimeToFlatten(tt.counts, 2, 1.2))
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+)
+
+// LoadMetrics describe how evenly the data points of a histogram are spread over its buckets.
+type LoadMetrics struct {
+	Total                  int     `json:"total"`
+	Buckets                int     `json:"buckets"`
+	BucketWidthMillis      int     `json:"bucketWidthMillis"`
+	PeakToMean             float64 `json:"peakToMean"`
+	CoefficientOfVariation float64 `json:"coefficientOfVariation"`
+	WindowMillis           int     `json:"windowMillis"`
+	MaxInWindow            int     `json:"maxInWindow"` // The most data points in any window of consecutive buckets
+	P50                    float64 `json:"p50"`         // Percentiles of the bucket counts
+	P90                    float64 `json:"p90"`
+	P99                    float64 `json:"p99"`
+	Max                    float64 `json:"max"`
+	ChiSquare              float64 `json:"chiSquare"` // Pearson's chi-square statistic against equal counts in all buckets
+	DegreesOfFreedom       int     `json:"degreesOfFreedom"`
+	PValue                 float64 `json:"pValue"` // How likely a uniform load has a statistic at least this large
+}
+
+// NewLoadMetrics computes the metrics of the histogram. The sliding window must be a multiple of the bucket width.
+// It returns an error if the histogram has no data points.
+func NewLoadMetrics(hist *histogram.Histogram, windowMillis int) (LoadMetrics, error) {
+	counts := hist.Data()
+	total := hist.TotalCount()
+	if total == 0 {
+		return LoadMetrics{}, fmt.Errorf("no data points")
+	}
+	if windowMillis <= 0 || windowMillis%hist.BucketWidth() != 0 || windowMillis/hist.BucketWidth() > len(counts) {
+		return LoadMetrics{}, fmt.Errorf("the window of %dms is not a multiple of the bucket width of %dms within the histogram", windowMillis, hist.BucketWidth())
+	}
+
+	values := make([]float64, len(counts))
+	for i, count := range counts {
+		values[i] = float64(count)
+	}
+	res := LoadMetrics{
+		Total:                  total,
+		Buckets:                len(counts),
+		BucketWidthMillis:      hist.BucketWidth(),
+		PeakToMean:             PeakToMean(counts),
+		CoefficientOfVariation: CoefficientOfVariation(counts),
+		WindowMillis:           windowMillis,
+		MaxInWindow:            MaxInWindow(counts, windowMillis/hist.BucketWidth()),
+		P50:                    Percentile(values, 50),
+		P90:                    Percentile(values, 90),
+		P99:                    Percentile(values, 99),
+		Max:                    Percentile(values, 100),
+		DegreesOfFreedom:       len(counts) - 1,
+	}
+	expected := float64(total) / float64(len(counts))
+	for _, count := range counts {
+		res.ChiSquare += (float64(count) - expected) * (float64(count) - expected) / expected
+	}
+	res.PValue = ChiSquarePValue(res.ChiSquare, res.DegreesOfFreedom)
+	return res, nil
+}
+
+// MaxInWindow returns the most data points in any window of the given number of consecutive buckets.
+func MaxInWindow(counts []int, window int) int {
+	if window <= 0 || window > len(counts) {
+		return 0
+	}
+	sum := 0
+	for _, count := range counts[:window] {
+		sum += count
+	}
+	res := sum
+	for i := window; i < len(counts); i++ {
+		sum += counts[i] - counts[i-window]
+		res = max(res, sum)
+	}
+	return res
+}
+
+// ChiSquarePValue returns the probability that a chi-square distributed value with the given degrees of freedom is at least the statistic.
+// It returns NaN without degrees of freedom.
+func ChiSquarePValue(statistic float64, degreesOfFreedom int) float64 {
+	if degreesOfFreedom <= 0 {
+		return math.NaN()
+	}
+	return upperIncompleteGamma(float64(degreesOfFreedom)/2, statistic/2)
+}
+
+const (
+	gammaEpsilon       = 1e-15
+	gammaMaxIterations = 1000000
+)
+
+// upperIncompleteGamma returns the regularized upper incomplete gamma function Q(a, x), by its series for small x,
+// and by its continued fraction otherwise.
+func upperIncompleteGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lg, _ := math.Lgamma(a)
+	logPrefix := a*math.Log(x) - x - lg
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n < gammaMaxIterations && math.Abs(term) > math.Abs(sum)*gammaEpsilon; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+		}
+		return math.Max(0, 1-sum*math.Exp(logPrefix))
+	}
+	// Modified Lentz's method
+	tiny := 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < gammaMaxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(logPrefix) * h
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChiSquarePValue(t *testing.T) {
+	tests := []struct {
+		statistic        float64
+		degreesOfFreedom int
+		expected         float64
+	}{
+		{0, 5, 1},
+		{3.841458820694124, 1, 0.05},
+		{6.634896601021214, 1, 0.01},
+		{4, 2, math.Exp(-2)},
+		{30, 2, math.Exp(-15)},
+		{18.307038053275146, 10, 0.05},
+		{1074.679, 999, 0.0477625578}, // Large degrees of freedom, as with a thousand buckets
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.expected, ChiSquarePValue(tt.statistic, tt.degreesOfFreedom), tt.expected*1e-4+1e-12, "statistic %f, degrees of freedom %d", tt.statistic, tt.degreesOfFreedom)
+	}
+	assert.True(t, math.IsNaN(ChiSquarePValue(1, 0)))
+}
+
+func TestMaxInWindow(t *testing.T) {
+	counts := []int{1, 5, 0, 3, 4, 0}
+	assert.Equal(t, 5, MaxInWindow(counts, 1))
+	assert.Equal(t, 7, MaxInWindow(counts, 2))
+	assert.Equal(t, 13, MaxInWindow(counts, 6))
+	assert.Equal(t, 0, MaxInWindow(counts, 7))
+}
+
+func TestNewLoadMetrics(t *testing.T) {
+	hist := histogram.NewHistogram(0, 1000, 4)
+	for _, at := range []int{0, 1000, 1500, 2000, 2100, 2200, 3000, 3999} {
+		hist.AddDataPoint(at)
+	}
+
+	metrics, err := NewLoadMetrics(hist, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, metrics.Total)
+	assert.Equal(t, 4, metrics.Buckets)
+	assert.Equal(t, 1.5, metrics.PeakToMean)
+	assert.Equal(t, 5, metrics.MaxInWindow)
+	assert.Equal(t, 2.0, metrics.P50)
+	assert.Equal(t, 3.0, metrics.Max)
+	// Counts 1, 2, 3, 2 against 2 each
+	assert.InDelta(t, 1.0, metrics.ChiSquare, 1e-9)
+	assert.Equal(t, 3, metrics.DegreesOfFreedom)
+	assert.InDelta(t, 0.8013, metrics.PValue, 1e-4)
+
+	_, err = NewLoadMetrics(hist, 1500)
+	assert.Error(t, err)
+	_, err = NewLoadMetrics(histogram.NewHistogram(0, 1000, 4), 1000)
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"math"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// offPeriodToleranceMillis is how far an interval may differ from the period without counting as off-period,
+// e.g. because the jitter strategies work with nanoseconds.
+const offPeriodToleranceMillis = 1e-3
+
+// ObjectStats describe the timeline of one object. Schedules that retry a failed reconciliation,
+// and the intervals leading to them, are left out. The interval after a retry starts at the retry,
+// so the drift includes how far retries, like jitter, queueing and restarts, moved the object.
+// The CSV file doesn't tell what moved a schedule, so every interval that differs from the period counts as off-period:
+// besides the jitter, watch events, restarts, queueing, rate limiting and retries shorten or stretch intervals too.
+type ObjectStats struct {
+	ID        int
+	Period    float64
+	Intervals []float64 // Between consecutive schedules, in milliseconds
+	OffPeriod int       // Intervals that differ from the period
+	Drift     float64   // How far the last schedule is from the grid of the first schedule and the period, in milliseconds
+}
+
+// NewObjectStats computes the statistics of the schedules of the object.
+func NewObjectStats(o *model.Object) ObjectStats {
+	res := ObjectStats{ID: o.ID(), Period: o.Period()}
+	schedules := o.Schedules()
+	last := 0
+	for i := 1; i < len(schedules); i++ {
+		if o.IsRetry(i) {
+			continue
+		}
+		interval := schedules[i] - schedules[i-1]
+		res.Intervals = append(res.Intervals, interval)
+		last = i
+	}
+	res.OffPeriod = len(res.OffPeriodIntervals())
+	if len(schedules) > 0 {
+		res.Drift = schedules[last] - schedules[0] - float64(len(res.Intervals))*o.Period()
+	}
+	return res
+}
+
+// OffPeriodIntervals returns the intervals that differ from the period.
+func (s ObjectStats) OffPeriodIntervals() []float64 {
+	var res []float64
+	for _, interval := range s.Intervals {
+		if math.Abs(interval-s.Period) > offPeriodToleranceMillis {
+			res = append(res, interval)
+		}
+	}
+	return res
+}
+
+// Summary describes the distribution of some values.
+type Summary struct {
+	Count  int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	P1     float64
+	P50    float64
+	P99    float64
+	Max    float64
+}
+
+// Summarize returns the summary of the values, which are left unchanged. All but the count are NaN without values.
+func Summarize(values []float64) Summary {
+	sorted := append([]float64(nil), values...)
+	res := Summary{
+		Count: len(values),
+		Min:   Percentile(sorted, 0),
+		P1:    Percentile(sorted, 1),
+		P50:   Percentile(sorted, 50),
+		P99:   Percentile(sorted, 99),
+		Max:   Percentile(sorted, 100),
+		Mean:  math.NaN(),
+	}
+	if len(values) == 0 {
+		res.StdDev = math.NaN()
+		return res
+	}
+	res.Mean = 0
+	for _, val := range values {
+		res.Mean += val / float64(len(values))
+	}
+	for _, val := range values {
+		res.StdDev += (val - res.Mean) * (val - res.Mean) / float64(len(values))
+	}
+	res.StdDev = math.Sqrt(res.StdDev)
+	return res
+}
+
+// PopulationStats summarize the statistics of all objects.
+type PopulationStats struct {
+	Objects            int
+	Intervals          Summary // All intervals of all objects
+	OffPeriod          int
+	OffPeriodShare     float64 // Of all intervals
+	OffPeriodPerObject Summary
+	Drift              Summary
+}
+
+// NewPopulationStats summarizes the statistics of the objects.
+func NewPopulationStats(objects []ObjectStats) PopulationStats {
+	var intervals, offPeriod, drifts []float64
+	res := PopulationStats{Objects: len(objects)}
+	for _, o := range objects {
+		intervals = append(intervals, o.Intervals...)
+		offPeriod = append(offPeriod, float64(o.OffPeriod))
+		drifts = append(drifts, o.Drift)
+		res.OffPeriod += o.OffPeriod
+	}
+	res.Intervals = Summarize(intervals)
+	res.OffPeriodPerObject = Summarize(offPeriod)
+	res.Drift = Summarize(drifts)
+	if len(intervals) > 0 {
+		res.OffPeriodShare = float64(res.OffPeriod) / float64(len(intervals))
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewObjectStats(t *testing.T) {
+	// One interval is jittered, then a failed reconciliation is retried, which moves the next schedule too
+	objects, err := model.UnmarshalObjSet(strings.NewReader("#format=2\n7,1000,0,,100,1100,2200,3200,3300r,4300,5300.0001\n"))
+	assert.NoError(t, err)
+
+	stats := NewObjectStats(objects[0])
+	assert.Equal(t, 7, stats.ID)
+	assert.Equal(t, 1000.0, stats.Period)
+	assert.InDeltaSlice(t, []float64{1000, 1100, 1000, 1000, 1000.0001}, stats.Intervals, 1e-9)
+	assert.Equal(t, 1, stats.OffPeriod)
+	assert.Equal(t, []float64{1100}, stats.OffPeriodIntervals())
+	assert.InDelta(t, 200.0001, stats.Drift, 1e-9)
+}
+
+func TestWatchEventsAndRestartsAreOffPeriod(t *testing.T) {
+	obj := model.NewObject(7, 100, 0, 0).SetPeriod(1000).SetRandomSupport(model.NewSeededRandomSupport(1))
+	obj.AddRandomSchedule()
+	obj.Trigger(1600) // A watch event
+	obj.AddRandomSchedule()
+	obj.RestartSchedule(2900, model.AllAtOncePlacement{}, 0, 1)
+	obj.AddRandomSchedule()
+
+	// Nothing is jittered, but the watch event and the restart shorten two intervals
+	stats := NewObjectStats(obj)
+	assert.Equal(t, []float64{1000, 500, 1000, 300, 1000}, stats.Intervals)
+	assert.Equal(t, 2, stats.OffPeriod)
+	assert.Equal(t, []float64{500, 300}, stats.OffPeriodIntervals())
+}
+
+func TestNewPopulationStats(t *testing.T) {
+	population := NewPopulationStats([]ObjectStats{
+		{ID: 1, Period: 1000, Intervals: []float64{1000, 1200}, OffPeriod: 1, Drift: 200},
+		{ID: 2, Period: 1000, Intervals: []float64{900, 1000}, OffPeriod: 1, Drift: -100},
+		{ID: 3, Period: 1000},
+	})
+	assert.Equal(t, 3, population.Objects)
+	assert.Equal(t, 4, population.Intervals.Count)
+	assert.Equal(t, 1025.0, population.Intervals.Mean)
+	assert.Equal(t, 2, population.OffPeriod)
+	assert.Equal(t, 0.5, population.OffPeriodShare)
+	assert.Equal(t, 0.0, population.OffPeriodPerObject.Min)
+	assert.Equal(t, 200.0, population.Drift.Max)
+	assert.Equal(t, 0.0, population.Drift.P50)
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]float64{4, 1, 3, 2})
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, 2.5, summary.Mean)
+	assert.InDelta(t, math.Sqrt(1.25), summary.StdDev, 1e-9)
+	assert.Equal(t, 1.0, summary.Min)
+	assert.Equal(t, 2.5, summary.P50)
+	assert.Equal(t, 4.0, summary.Max)
+
+	empty := Summarize(nil)
+	assert.Equal(t, 0, empty.Count)
+	assert.True(t, math.IsNaN(empty.Mean))
+	assert.True(t, math.IsNaN(empty.StdDev))
+}
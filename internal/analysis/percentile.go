@@ -0,0 +1,19 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values, interpolating linearly between the closest ranks.
+// It returns NaN for an empty slice. The values are sorted in place.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return values[lower] + (rank-float64(lower))*(values[upper]-values[lower])
+}
@@ -0,0 +1,52 @@
+package analysis
+
+import "math"
+
+// PhaseHeatmap counts data points by the elapsed period and by the phase within the period.
+// Schedules that keep their phase stay in one row, so it shows whether a herd stays clustered or smears out over time.
+type PhaseHeatmap struct {
+	periodMillis float64
+	phaseBuckets int
+	counts       [][]int // By elapsed period, then by phase bucket
+	maxCount     int
+}
+
+// NewPhaseHeatmap creates a heatmap of the given number of periods from time zero, each split into phaseBuckets rows.
+func NewPhaseHeatmap(periodMillis float64, periods, phaseBuckets int) *PhaseHeatmap {
+	counts := make([][]int, periods)
+	for i := range counts {
+		counts[i] = make([]int, phaseBuckets)
+	}
+	return &PhaseHeatmap{
+		periodMillis: periodMillis,
+		phaseBuckets: phaseBuckets,
+		counts:       counts,
+	}
+}
+
+// Contains tells whether the given time is within the periods of the heatmap.
+func (h *PhaseHeatmap) Contains(timeMillis float64) bool {
+	return timeMillis >= 0 && timeMillis < h.periodMillis*float64(len(h.counts))
+}
+
+// AddDataPoint counts a data point at the given time, which must be within the heatmap.
+func (h *PhaseHeatmap) AddDataPoint(timeMillis float64) {
+	if !h.Contains(timeMillis) {
+		panic("Time is outside of the heatmap range")
+	}
+	period := int(timeMillis / h.periodMillis)
+	phase := math.Mod(timeMillis, h.periodMillis) / h.periodMillis
+	bucket := min(int(phase*float64(h.phaseBuckets)), h.phaseBuckets-1)
+	h.counts[period][bucket]++
+	h.maxCount = max(h.maxCount, h.counts[period][bucket])
+}
+
+// Counts returns the count of every cell, by elapsed period and then by phase bucket.
+func (h *PhaseHeatmap) Counts() [][]int {
+	return h.counts
+}
+
+// MaxCount returns the highest count of a cell.
+func (h *PhaseHeatmap) MaxCount() int {
+	return h.maxCount
+}
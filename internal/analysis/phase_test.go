@@ -0,0 +1,22 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseHeatmap(t *testing.T) {
+	heatmap := NewPhaseHeatmap(1000, 3, 4)
+	for _, at := range []float64{0, 10, 260, 999.9, 1000, 1010, 2250, 2500, 2750} {
+		heatmap.AddDataPoint(at)
+	}
+
+	assert.Equal(t, [][]int{{2, 1, 0, 1}, {2, 0, 0, 0}, {0, 1, 1, 1}}, heatmap.Counts())
+	assert.Equal(t, 2, heatmap.MaxCount())
+
+	assert.True(t, heatmap.Contains(2999))
+	assert.False(t, heatmap.Contains(3000))
+	assert.False(t, heatmap.Contains(-1))
+	assert.Panics(t, func() { heatmap.AddDataPoint(3000) })
+}
@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// queueChange is a change of the queue depth at a given time.
+type queueChange struct {
+	time  float64
+	delta int
+}
+
+// QueueDepth returns the maximum number of objects waiting for a worker in every bucket of the time range
+// [fromMillis, fromMillis + bucketWidthMillis*bucketCount). An object waits from the time it passed the rate limiters until a worker starts processing it.
+func QueueDepth(reconciles []model.Reconcile, fromMillis, bucketWidthMillis float64, bucketCount int) []float64 {
+	changes := make([]queueChange, 0, 2*len(reconciles))
+	for _, r := range reconciles {
+		if r.Start > r.Queued() {
+			changes = append(changes, queueChange{r.Queued(), 1}, queueChange{r.Start, -1})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].time == changes[j].time {
+			return changes[i].delta < changes[j].delta // Leaving the queue first, so that the depth is never overestimated
+		}
+		return changes[i].time < changes[j].time
+	})
+
+	res := make([]float64, bucketCount)
+	depth, idx := 0, 0
+	for b := 0; b < bucketCount; b++ {
+		bucketStart := fromMillis + bucketWidthMillis*float64(b)
+		bucketEnd := bucketStart + bucketWidthMillis
+		for ; idx < len(changes) && changes[idx].time <= bucketStart; idx++ {
+			depth += changes[idx].delta
+		}
+		maxDepth := depth
+		for ; idx < len(changes) && changes[idx].time < bucketEnd; idx++ {
+			depth += changes[idx].delta
+			maxDepth = max(maxDepth, depth)
+		}
+		res[b] = float64(maxDepth)
+	}
+	return res
+}
+
+// WaitPercentiles returns, for every percentile, the wait time percentile of the reconciles started in every bucket of the time range
+// [fromMillis, fromMillis + bucketWidthMillis*bucketCount). Buckets without reconciles have a zero wait time.
+func WaitPercentiles(reconciles []model.Reconcile, fromMillis, bucketWidthMillis float64, bucketCount int, percentiles []float64) [][]float64 {
+	waits := make([][]float64, bucketCount)
+	for _, r := range reconciles {
+		idx := int((r.Start - fromMillis) / bucketWidthMillis)
+		if r.Start < fromMillis || idx >= bucketCount {
+			continue
+		}
+		waits[idx] = append(waits[idx], r.Wait())
+	}
+
+	res := make([][]float64, len(percentiles))
+	for i, p := range percentiles {
+		res[i] = make([]float64, bucketCount)
+		for b := range waits {
+			if len(waits[b]) > 0 {
+				res[i][b] = Percentile(waits[b], p)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.True(t, math.IsNaN(Percentile([]float64{}, 50)))
+	assert.Equal(t, 3.0, Percentile([]float64{3}, 99))
+	assert.Equal(t, 2.5, Percentile([]float64{4, 1, 3, 2}, 50))
+	assert.Equal(t, 1.0, Percentile([]float64{4, 1, 3, 2}, 0))
+	assert.Equal(t, 4.0, Percentile([]float64{4, 1, 3, 2}, 100))
+}
+
+func TestQueueDepth(t *testing.T) {
+	reconciles := []model.Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10},
+		{ObjectID: 2, Due: 0, Start: 10, Finish: 20},
+		{ObjectID: 3, Due: 5, Start: 20, Finish: 30},
+		{ObjectID: 4, Due: 25, Start: 30, Finish: 40},
+	}
+	assert.Equal(t, []float64{2, 1, 1, 0}, QueueDepth(reconciles, 0, 10, 4))
+}
+
+func TestWaitPercentiles(t *testing.T) {
+	reconciles := []model.Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10},
+		{ObjectID: 2, Due: 0, Start: 4, Finish: 20},
+		{ObjectID: 3, Due: 5, Start: 25, Finish: 30},
+	}
+	assert.Equal(t, [][]float64{{2, 0, 20}, {4, 0, 20}}, WaitPercentiles(reconciles, 0, 10, 3, []float64{50, 100}))
+}
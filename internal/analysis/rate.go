@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+)
+
+// PeakRate is the highest rate of data points in any sliding window of the given length.
+type PeakRate struct {
+	WindowMillis int     `json:"windowMillis"`
+	PerSecond    float64 `json:"perSecond"`
+}
+
+// PerSecond converts a count of data points in the given time range to a rate in events per second.
+func PerSecond(count float64, millis int) float64 {
+	return count * 1000 / float64(millis)
+}
+
+// PeakRates returns the peak rate of the histogram for every window, which must be a multiple of the bucket width within the histogram.
+// Unlike the bucket counts, the rates don't depend on the bucket width, so they are comparable across histograms.
+func PeakRates(hist *histogram.Histogram, windowsMillis []int) ([]PeakRate, error) {
+	res := make([]PeakRate, len(windowsMillis))
+	for i, windowMillis := range windowsMillis {
+		if windowMillis <= 0 || windowMillis%hist.BucketWidth() != 0 || windowMillis/hist.BucketWidth() > hist.BucketCount() {
+			return nil, fmt.Errorf("the window of %dms is not a multiple of the bucket width of %dms within the histogram", windowMillis, hist.BucketWidth())
+		}
+		peak := MaxInWindow(hist.Data(), windowMillis/hist.BucketWidth())
+		res[i] = PeakRate{WindowMillis: windowMillis, PerSecond: PerSecond(float64(peak), windowMillis)}
+	}
+	return res, nil
+}
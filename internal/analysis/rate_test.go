@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeakRates(t *testing.T) {
+	hist := histogram.NewHistogram(0, 500, 8)
+	for _, at := range []int{0, 600, 700, 800, 900, 1200, 2100, 3999} {
+		hist.AddDataPoint(at)
+	}
+
+	rates, err := PeakRates(hist, []int{500, 1000, 4000})
+	assert.NoError(t, err)
+	assert.Equal(t, []PeakRate{
+		{WindowMillis: 500, PerSecond: 8},
+		{WindowMillis: 1000, PerSecond: 5},
+		{WindowMillis: 4000, PerSecond: 2},
+	}, rates)
+
+	_, err = PeakRates(hist, []int{750})
+	assert.Error(t, err)
+	_, err = PeakRates(hist, []int{4500})
+	assert.Error(t, err)
+}
+
+func TestPerSecond(t *testing.T) {
+	assert.Equal(t, 12.0, PerSecond(3, 250))
+	assert.Equal(t, 0.5, PerSecond(30, 60000))
+}
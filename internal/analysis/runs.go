@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"math"
+)
+
+// RunStats summarises every bucket of a histogram across several runs of the same simulation.
+type RunStats struct {
+	Mean []float64
+	Min  []float64
+	Max  []float64
+	Low  []float64 // Low percentile of every bucket
+	High []float64 // High percentile of every bucket
+}
+
+// AcrossRuns computes the statistics of every bucket across the runs, which must all have the same number of buckets.
+// The low and high percentiles (0 <= p <= 100) bound the band in which most runs are.
+func AcrossRuns(runs [][]int, lowPercentile, highPercentile float64) RunStats {
+	bucketCount := 0
+	if len(runs) > 0 {
+		bucketCount = len(runs[0])
+	}
+	res := RunStats{
+		Mean: make([]float64, bucketCount),
+		Min:  make([]float64, bucketCount),
+		Max:  make([]float64, bucketCount),
+		Low:  make([]float64, bucketCount),
+		High: make([]float64, bucketCount),
+	}
+	values := make([]float64, len(runs))
+	for b := 0; b < bucketCount; b++ {
+		sum := 0.0
+		res.Min[b], res.Max[b] = math.Inf(1), math.Inf(-1)
+		for r, run := range runs {
+			values[r] = float64(run[b])
+			sum += values[r]
+			res.Min[b] = min(res.Min[b], values[r])
+			res.Max[b] = max(res.Max[b], values[r])
+		}
+		res.Mean[b] = sum / float64(len(runs))
+		res.Low[b] = Percentile(values, lowPercentile)
+		res.High[b] = Percentile(values, highPercentile)
+	}
+	return res
+}
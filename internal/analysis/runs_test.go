@@ -0,0 +1,23 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcrossRuns(t *testing.T) {
+	stats := AcrossRuns([][]int{
+		{1, 10},
+		{3, 30},
+		{2, 20},
+		{4, 40},
+		{5, 0},
+	}, 25, 75)
+
+	assert.Equal(t, []float64{3, 20}, stats.Mean)
+	assert.Equal(t, []float64{1, 0}, stats.Min)
+	assert.Equal(t, []float64{5, 40}, stats.Max)
+	assert.Equal(t, []float64{2, 10}, stats.Low)
+	assert.Equal(t, []float64{4, 30}, stats.High)
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// ScheduleHistogram counts the schedules of the objects in a histogram with the given buckets.
+// Schedules outside of its range are left out.
+func ScheduleHistogram(objects model.ObjSet, fromMillis, bucketWidthMillis, bucketCount int) *histogram.Histogram {
+	return scheduleHistogram(objects, fromMillis, bucketWidthMillis, bucketCount, func(*model.Object, int) bool { return true })
+}
+
+// RetryHistogram counts the schedules that retry a failed reconciliation, like ScheduleHistogram.
+func RetryHistogram(objects model.ObjSet, fromMillis, bucketWidthMillis, bucketCount int) *histogram.Histogram {
+	return scheduleHistogram(objects, fromMillis, bucketWidthMillis, bucketCount, func(obj *model.Object, idx int) bool { return obj.IsRetry(idx) })
+}
+
+// SchedulePhaseHeatmap counts the schedules of the objects in a heatmap like NewPhaseHeatmap.
+// Schedules outside of its periods are left out.
+func SchedulePhaseHeatmap(objects model.ObjSet, periodMillis float64, periods, phaseBuckets int) *PhaseHeatmap {
+	heatmap := NewPhaseHeatmap(periodMillis, periods, phaseBuckets)
+	for _, obj := range objects {
+		for _, schedule := range obj.Schedules() {
+			if heatmap.Contains(schedule) {
+				heatmap.AddDataPoint(schedule)
+			}
+		}
+	}
+	return heatmap
+}
+
+// scheduleHistogram counts the schedules that the filter accepts. The filter gets the object and the index of the schedule.
+func scheduleHistogram(objects model.ObjSet, fromMillis, bucketWidthMillis, bucketCount int, filter func(obj *model.Object, idx int) bool) *histogram.Histogram {
+	hist := histogram.NewHistogram(fromMillis, bucketWidthMillis, bucketCount)
+	for _, obj := range objects {
+		for idx, schedule := range obj.Schedules() {
+			if hist.Contains(int(schedule)) && filter(obj, idx) {
+				hist.AddDataPoint(int(schedule))
+			}
+		}
+	}
+	return hist
+}
@@ -0,0 +1,18 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleHistograms(t *testing.T) {
+	objects, err := model.UnmarshalObjSet(strings.NewReader("#format=2\n1,1000,0,,100,1100,1150r,2150\n2,1000,0,,500.5,1500.5,2500.5\n"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 1}, ScheduleHistogram(objects, 500, 500, 3).Data())
+	assert.Equal(t, []int{0, 1, 0}, RetryHistogram(objects, 500, 500, 3).Data())
+	assert.Equal(t, [][]int{{1, 1}, {2, 1}, {1, 1}}, SchedulePhaseHeatmap(objects, 1000, 3, 2).Counts())
+}
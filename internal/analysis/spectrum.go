@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// PowerSpectrum returns the power |X_k|² of the discrete Fourier transform of the values, for the frequencies k = 0 to n/2,
+// where frequency k completes k cycles over all the values. It works for any length, fastest for lengths with small prime factors.
+func PowerSpectrum(values []float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	in := make([]complex128, len(values))
+	for i, val := range values {
+		in[i] = complex(val, 0)
+	}
+	out := fft(in)
+	res := make([]float64, len(values)/2+1)
+	for k := range res {
+		res[k] = real(out[k])*real(out[k]) + imag(out[k])*imag(out[k])
+	}
+	return res
+}
+
+// fft computes the discrete Fourier transform by splitting the values by their smallest prime factor (mixed-radix Cooley-Tukey).
+// A prime length is transformed directly.
+func fft(values []complex128) []complex128 {
+	n := len(values)
+	if n == 1 {
+		return []complex128{values[0]}
+	}
+	radix := smallestFactor(n)
+	m := n / radix
+
+	// Transform every subsequence of values with the same index modulo the radix
+	subs := make([][]complex128, radix)
+	for r := range subs {
+		sub := make([]complex128, m)
+		for i := range sub {
+			sub[i] = values[i*radix+r]
+		}
+		subs[r] = sub
+		if m > 1 {
+			subs[r] = fft(sub)
+		}
+	}
+
+	res := make([]complex128, n)
+	for k := range res {
+		var sum complex128
+		for r, sub := range subs {
+			sum += sub[k%m] * cmplx.Rect(1, -2*math.Pi*float64(r*k%n)/float64(n))
+		}
+		res[k] = sum
+	}
+	return res
+}
+
+func smallestFactor(n int) int {
+	for f := 2; f*f <= n; f++ {
+		if n%f == 0 {
+			return f
+		}
+	}
+	return n
+}
+
+// RelativePowerSpectrum returns the share of every frequency of PowerSpectrum in the power of the counts without their mean,
+// counting the negative frequencies too. The share of the mean itself, at frequency 0, is 0. A constant load has no power at all.
+func RelativePowerSpectrum(counts []int) []float64 {
+	values := make([]float64, len(counts))
+	for i, count := range counts {
+		values[i] = float64(count)
+	}
+	res := PowerSpectrum(values)
+	if len(res) == 0 {
+		return res
+	}
+	res[0] = 0
+	for k := 1; k < len(res); k++ {
+		if 2*k != len(counts) {
+			res[k] *= 2 // The negative frequency has the same power
+		}
+	}
+	// By Parseval's theorem, the power of all frequencies but 0 is n times the sum of the squared deviations from the mean
+	mean := 0.0
+	for _, val := range values {
+		mean += val / float64(len(values))
+	}
+	total := 0.0
+	for _, val := range values {
+		total += float64(len(values)) * (val - mean) * (val - mean)
+	}
+	for k := range res {
+		if total > 0 {
+			res[k] /= total
+		} else {
+			res[k] = 0
+		}
+	}
+	return res
+}
+
+// SynchronisationIndex returns the share of the power of the counts without their mean at the period and its harmonics,
+// up to the given number of harmonics including the period itself, or up to the highest frequency if harmonics is not positive.
+// The counts must cover a whole number of periods. Objects in phase make a load that repeats every period, with an index close to 1,
+// whatever the shape of the load within the period. A flat, random load has an index of about the share of the frequencies
+// taken into account, one over the number of periods with all harmonics.
+// It returns 0 for a constant load, and NaN if the counts don't cover a whole number of periods.
+func SynchronisationIndex(counts []int, periodBuckets, harmonics int) float64 {
+	if periodBuckets <= 0 || len(counts) == 0 || len(counts)%periodBuckets != 0 {
+		return math.NaN()
+	}
+	spectrum := RelativePowerSpectrum(counts)
+	base := len(counts) / periodBuckets
+	res := 0.0
+	for h := 1; (harmonics <= 0 || h <= harmonics) && h*base < len(spectrum); h++ {
+		res += spectrum[h*base]
+	}
+	return res
+}
+
+// SynchronisationOverTime returns the synchronisation index of the windows of the given number of periods, starting at every period.
+// It returns nothing if a window doesn't fit into the counts.
+func SynchronisationOverTime(counts []int, periodBuckets, windowPeriods, harmonics int) []float64 {
+	window := periodBuckets * windowPeriods
+	if periodBuckets <= 0 || window <= 0 || window > len(counts) {
+		return nil
+	}
+	var res []float64
+	for from := 0; from+window <= len(counts); from += periodBuckets {
+		res = append(res, SynchronisationIndex(counts[from:from+window], periodBuckets, harmonics))
+	}
+	return res
+}
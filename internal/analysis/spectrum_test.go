@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPowerSpectrumMatchesDirectTransform(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{1, 2, 7, 12, 30, 64, 97} {
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = rnd.Float64()
+		}
+
+		spectrum := PowerSpectrum(values)
+		assert.Len(t, spectrum, n/2+1)
+		for k := range spectrum {
+			var sum complex128
+			for i, val := range values {
+				sum += complex(val, 0) * cmplx.Rect(1, -2*math.Pi*float64(i*k)/float64(n))
+			}
+			assert.InDelta(t, cmplx.Abs(sum)*cmplx.Abs(sum), spectrum[k], 1e-9, "length %d, frequency %d", n, k)
+		}
+	}
+}
+
+func TestRelativePowerSpectrum(t *testing.T) {
+	// A wave of four cycles has all its power at frequency 4
+	counts := make([]int, 32)
+	for i := range counts {
+		counts[i] = int(math.Round(100 + 50*math.Cos(2*math.Pi*4*float64(i)/32)))
+	}
+	spectrum := RelativePowerSpectrum(counts)
+	assert.InDelta(t, 1, spectrum[4], 1e-3)
+	assert.Equal(t, 0.0, spectrum[0])
+
+	total := 0.0
+	for _, share := range RelativePowerSpectrum([]int{3, 1, 4, 1, 5, 9, 2, 6}) {
+		total += share
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+
+	assert.Equal(t, []float64{0, 0, 0}, RelativePowerSpectrum([]int{5, 5, 5, 5}))
+}
+
+func TestSynchronisationIndex(t *testing.T) {
+	// All objects in phase, in the same bucket of every period of ten buckets
+	inPhase := make([]int, 100)
+	for i := 0; i < len(inPhase); i += 10 {
+		inPhase[i] = 1000
+	}
+	assert.InDelta(t, 1, SynchronisationIndex(inPhase, 10, 5), 1e-9)
+	assert.InDelta(t, 4.0/9, SynchronisationIndex(inPhase, 10, 2), 1e-9) // The fifth harmonic has no negative frequency
+	assert.InDelta(t, 1, SynchronisationIndex(inPhase, 10, 0), 1e-9)
+
+	// A random load has about the share of the frequencies taken into account
+	rnd := rand.New(rand.NewPCG(1, 2))
+	random := make([]int, 1000)
+	for i := 0; i < 100000; i++ {
+		random[rnd.IntN(len(random))]++
+	}
+	assert.Less(t, SynchronisationIndex(random, 10, 5), 0.05)
+	assert.InDelta(t, 0.1, SynchronisationIndex(random, 100, 0), 0.05)
+
+	assert.Equal(t, 0.0, SynchronisationIndex([]int{5, 5, 5, 5}, 2, 1))
+	assert.True(t, math.IsNaN(SynchronisationIndex(inPhase, 30, 1)))
+}
+
+func TestSynchronisationOverTime(t *testing.T) {
+	counts := make([]int, 60)
+	for i := 0; i < 30; i += 10 {
+		counts[i] = 1000
+	}
+	for i := 30; i < len(counts); i++ {
+		counts[i] = 100
+	}
+
+	values := SynchronisationOverTime(counts, 10, 2, 5)
+	assert.Len(t, values, 5)
+	assert.InDelta(t, 1, values[0], 1e-9)
+	assert.InDelta(t, 1, values[1], 1e-9)
+	assert.Equal(t, 0.0, values[4])
+	assert.Nil(t, SynchronisationOverTime(counts, 10, 7, 5))
+}
@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+)
+
+// UniformityMetric measures how far the counts of a window of buckets are from a flat load. The lower the value, the flatter the load.
+// It returns NaN if there are no data points.
+type UniformityMetric func(counts []int) float64
+
+// UniformityMetricNames lists the names accepted by NewUniformityMetric.
+var UniformityMetricNames = []string{"cv", "kl", "peak-mean"}
+
+// NewUniformityMetric returns the metric with the given name.
+func NewUniformityMetric(name string) (UniformityMetric, error) {
+	switch name {
+	case "cv":
+		return CoefficientOfVariation, nil
+	case "kl":
+		return KLDivergenceFromUniform, nil
+	case "peak-mean":
+		return PeakToMean, nil
+	}
+	return nil, fmt.Errorf("unknown uniformity metric: %s", name)
+}
+
+// CoefficientOfVariation returns the standard deviation of the counts divided by their mean. A perfectly flat load has a coefficient of 0.
+// It returns NaN if there are no data points.
+func CoefficientOfVariation(counts []int) float64 {
+	mean := Mean(counts)
+	if math.IsNaN(mean) || mean == 0 {
+		return math.NaN()
+	}
+	variance := 0.0
+	for _, count := range counts {
+		variance += (float64(count) - mean) * (float64(count) - mean)
+	}
+	variance /= float64(len(counts))
+	return math.Sqrt(variance) / mean
+}
+
+// KLDivergenceFromUniform returns the Kullback-Leibler divergence of the distribution of the data points over the buckets
+// from the uniform distribution, in nats. A perfectly flat load has a divergence of 0.
+// It returns NaN if there are no data points.
+func KLDivergenceFromUniform(counts []int) float64 {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+	res := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			share := float64(count) / float64(total)
+			res += share * math.Log(share*float64(len(counts)))
+		}
+	}
+	return res
+}
+
+// SlidingWindows returns the metric of every window of the given number of buckets, starting at every bucket.
+// It returns nothing if the window doesn't fit into the counts.
+func SlidingWindows(counts []int, window int, metric UniformityMetric) []float64 {
+	if window <= 0 || window > len(counts) {
+		return nil
+	}
+	res := make([]float64, len(counts)-window+1)
+	for start := range res {
+		res[start] = metric(counts[start : start+window])
+	}
+	return res
+}
+
+// TimeToUniformity returns the index of the first value from which all values are at most the threshold,
+// e.g. the first window of SlidingWindows from which the load stays flat. It returns -1 if the values never get there.
+func TimeToUniformity(values []float64, threshold float64) int {
+	res := -1
+	// Walk backwards, so that the result is the start of the last run of values below the threshold
+	for i := len(values) - 1; i >= 0; i-- {
+		if math.IsNaN(values[i]) || values[i] > threshold {
+			break
+		}
+		res = i
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniformityMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		cv     float64
+		kl     float64
+	}{
+		{"flat", []int{5, 5, 5, 5}, 0, 0},
+		{"all in one bucket", []int{0, 8, 0, 0}, math.Sqrt(3), math.Log(4)},
+		{"half of the buckets", []int{6, 0, 6, 0}, 1, math.Log(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.cv, CoefficientOfVariation(tt.counts), 1e-9)
+			assert.InDelta(t, tt.kl, KLDivergenceFromUniform(tt.counts), 1e-9)
+		})
+	}
+	assert.True(t, math.IsNaN(CoefficientOfVariation([]int{0, 0})))
+	assert.True(t, math.IsNaN(KLDivergenceFromUniform([]int{})))
+}
+
+func TestNewUniformityMetric(t *testing.T) {
+	for _, name := range UniformityMetricNames {
+		metric, err := NewUniformityMetric(name)
+		assert.NoError(t, err)
+		assert.NotNil(t, metric)
+	}
+	_, err := NewUniformityMetric("entropy")
+	assert.Error(t, err)
+}
+
+func TestTimeToUniformity(t *testing.T) {
+	counts := []int{0, 20, 0, 5, 4, 5, 4}
+	values := SlidingWindows(counts, 2, CoefficientOfVariation)
+	assert.Len(t, values, 6)
+	assert.InDelta(t, 1.0, values[0], 1e-9)
+	assert.Equal(t, 0, TimeToUniformity(values, 1.0))
+	assert.Equal(t, 3, TimeToUniformity(values, 0.2))
+	assert.Equal(t, -1, TimeToUniformity(values, 0.1))
+	assert.Equal(t, -1, TimeToUniformity([]float64{0, math.NaN()}, 1))
+	assert.Nil(t, SlidingWindows(counts, 8, CoefficientOfVariation))
+}
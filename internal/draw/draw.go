@@ -2,7 +2,10 @@ package draw
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/analysis"
 	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
 
 	"github.com/fogleman/gg"
@@ -20,19 +23,90 @@ const (
 	lineThickness         float64 = 1.5
 )
 
+// Line is a data series drawn by DrawLines. The values are spread evenly over the width of the graph.
+type Line struct {
+	Label   string
+	Values  []float64
+	R, G, B float64
+}
+
+// lineColors are the colors of LineColor, repeated if there are more lines.
+var lineColors = [][3]float64{{0, 200.0 / 255.0, 0}, {1, 200.0 / 255.0, 0}, {1, 80.0 / 255.0, 80.0 / 255.0}, {80.0 / 255.0, 160.0 / 255.0, 1}, {200.0 / 255.0, 100.0 / 255.0, 1}, {1, 1, 1}}
+
+// LineColor returns the color of the i-th of several lines drawn in the same chart.
+func LineColor(i int) (r, g, b float64) {
+	color := lineColors[i%len(lineColors)]
+	return color[0], color[1], color[2]
+}
+
+// Band is a range of values drawn as a shaded area by DrawBands, e.g. the spread of a histogram across several simulation runs.
+type Band struct {
+	Label      string
+	Low, High  []float64
+	R, G, B, A float64
+}
+
 func Draw(hist *histogram.Histogram, startLabel, endLabel string, outputFileName string) {
-	dc := gg.NewContext(int(graphWidth+horizontalMarginLeft+horizontalMarginRight), int(graphHeight+verticalMarginTop+verticalMarginBottom))
+	DrawWithHighlight(hist, nil, startLabel, endLabel, outputFileName)
+}
 
-	// Set the background
-	dc.SetRGB(0.1, 0.1, 0.1)
-	dc.Clear()
-	dc.Fill()
+// DrawWithHighlight draws the histogram like Draw, and the highlight histogram over it in a different colour.
+// The highlight must have the same buckets and count a subset of the data points, e.g. only the retries.
+func DrawWithHighlight(hist, highlight *histogram.Histogram, startLabel, endLabel string, outputFileName string) {
+	DrawWithPrediction(hist, highlight, nil, startLabel, endLabel, outputFileName)
+}
+
+// DrawWithPrediction draws the histograms like DrawWithHighlight, and the predicted count of every bucket as a line over them.
+// The highlight and the prediction are optional. The vertical axis is labelled with the rate in events per second,
+// so that graphs with different bucket widths are comparable.
+func DrawWithPrediction(hist, highlight *histogram.Histogram, prediction []float64, startLabel, endLabel string, outputFileName string) {
+	drawHistogram(hist, highlight, prediction, func(maxHeight float64) string {
+		return formatValue(analysis.PerSecond(maxHeight, hist.BucketWidth())) + "/s"
+	}, startLabel, startLabel+"+"+endLabel, outputFileName)
+}
+
+// DrawDistribution draws the histogram of values other than times, e.g. of the intervals between schedules.
+// The vertical axis is labelled with the count of the highest bucket, the horizontal axis with the first and last labels.
+func DrawDistribution(hist *histogram.Histogram, firstLabel, lastLabel string, outputFileName string) {
+	drawHistogram(hist, nil, nil, func(maxHeight float64) string {
+		return fmt.Sprintf("%.0f", maxHeight)
+	}, firstLabel, lastLabel, outputFileName)
+}
+
+func drawHistogram(hist, highlight *histogram.Histogram, prediction []float64, topLabel func(maxHeight float64) string, leftLabel, rightLabel string, outputFileName string) {
+	dc := newCanvas()
 
 	// Draw the histogram
 	maxHeight := float64(hist.MaxHeight())
+	for _, val := range prediction {
+		maxHeight = max(maxHeight, val)
+	}
 
 	dc.SetLineWidth(lineThickness)
 	dc.SetRGB(float64(0)/255.0, float64(200.0)/255.0, float64(0)/255.0)
+	drawBars(dc, hist, maxHeight)
+	if highlight != nil {
+		dc.SetRGB(float64(255.0)/255.0, float64(140.0)/255.0, float64(0)/255.0)
+		drawBars(dc, highlight, maxHeight)
+	}
+	if prediction != nil {
+		dc.SetRGB(1, 1, 1)
+		for x, val := range prediction {
+			dc.LineTo(horizontalMarginLeft+float64(x), graphHeight+verticalMarginTop-(val/maxHeight)*graphHeight)
+		}
+		dc.Stroke()
+	}
+
+	drawFrame(dc, topLabel(maxHeight), leftLabel, rightLabel)
+	if prediction != nil {
+		dc.SetRGB(1, 1, 1)
+		dc.DrawStringAnchored("predicted", horizontalMarginLeft+graphWidth+10, verticalMarginTop+30, 0.0, 0.0)
+	}
+	dc.SavePNG(outputFileName)
+}
+
+// drawBars draws a vertical line for every bucket of the histogram, scaled so that maxHeight fills the graph.
+func drawBars(dc *gg.Context, hist *histogram.Histogram, maxHeight float64) {
 	for x := 0; x < hist.BucketCount(); x++ {
 		y := (float64(hist.Data()[x]) / maxHeight) * (graphHeight)
 		x1 := horizontalMarginLeft + float64(x)
@@ -42,8 +116,194 @@ func Draw(hist *histogram.Histogram, startLabel, endLabel string, outputFileName
 		dc.DrawLine(x1, y1, x2, y2)
 		dc.Stroke()
 	}
+}
+
+// DrawLines draws the lines on a common vertical scale, from zero to the maximum value of all lines.
+// The unit is appended to the label of the maximum value.
+func DrawLines(lines []Line, unit string, startLabel, endLabel string, outputFileName string) {
+	drawLines(lines, unit, startLabel, startLabel+"+"+endLabel, outputFileName)
+}
+
+// DrawLinesOver draws the lines like DrawLines, over a range of values of a parameter instead of over time, e.g. the results of a sweep.
+// The first and last labels are the values of the parameter at the left and right edges.
+func DrawLinesOver(lines []Line, unit string, firstLabel, lastLabel string, outputFileName string) {
+	drawLines(lines, unit, firstLabel, lastLabel, outputFileName)
+}
+
+func drawLines(lines []Line, unit string, leftLabel, rightLabel string, outputFileName string) {
+	dc := newCanvas()
+
+	maxValue := 0.0
+	for _, line := range lines {
+		for _, val := range line.Values {
+			maxValue = max(maxValue, val)
+		}
+	}
+
+	dc.SetLineWidth(lineThickness)
+	for _, line := range lines {
+		if len(line.Values) < 2 || maxValue == 0 {
+			continue
+		}
+		dc.SetRGB(line.R, line.G, line.B)
+		for i, val := range line.Values {
+			x := horizontalMarginLeft + graphWidth*float64(i)/float64(len(line.Values)-1)
+			y := graphHeight + verticalMarginTop - (val/maxValue)*graphHeight
+			dc.LineTo(x, y)
+		}
+		dc.Stroke()
+	}
+
+	drawFrame(dc, fmt.Sprintf("%.0f%s", maxValue, unit), leftLabel, rightLabel)
+
+	// Draw the legend
+	for i, line := range lines {
+		dc.SetRGB(line.R, line.G, line.B)
+		dc.DrawStringAnchored(line.Label, horizontalMarginLeft+graphWidth+10, verticalMarginTop+30+25*float64(i), 0.0, 0.0)
+	}
+
+	dc.SavePNG(outputFileName)
+}
+
+// DrawBands draws the bands as shaded areas, in the given order, and the line over them.
+// Everything is drawn on a common vertical scale, from zero to the maximum value of the bands and the line.
+// The unit is appended to the label of the maximum value.
+func DrawBands(bands []Band, line Line, unit string, startLabel, endLabel string, outputFileName string) {
+	dc := newCanvas()
+
+	maxValue := 0.0
+	for _, val := range line.Values {
+		maxValue = max(maxValue, val)
+	}
+	for _, band := range bands {
+		for _, val := range band.High {
+			maxValue = max(maxValue, val)
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+	toX := func(i, count int) float64 {
+		return horizontalMarginLeft + graphWidth*float64(i)/float64(count-1)
+	}
+	toY := func(val float64) float64 {
+		return graphHeight + verticalMarginTop - (val/maxValue)*graphHeight
+	}
+
+	// Draw the bands, along the high values to the right and back along the low values
+	for _, band := range bands {
+		if len(band.High) < 2 {
+			continue
+		}
+		dc.SetRGBA(band.R, band.G, band.B, band.A)
+		for i, val := range band.High {
+			dc.LineTo(toX(i, len(band.High)), toY(val))
+		}
+		for i := len(band.Low) - 1; i >= 0; i-- {
+			dc.LineTo(toX(i, len(band.Low)), toY(band.Low[i]))
+		}
+		dc.ClosePath()
+		dc.Fill()
+	}
+
+	dc.SetLineWidth(lineThickness)
+	if len(line.Values) >= 2 {
+		dc.SetRGB(line.R, line.G, line.B)
+		for i, val := range line.Values {
+			dc.LineTo(toX(i, len(line.Values)), toY(val))
+		}
+		dc.Stroke()
+	}
+
+	drawFrame(dc, formatValue(maxValue)+unit, startLabel, startLabel+"+"+endLabel)
+
+	// Draw the legend
+	dc.SetRGB(line.R, line.G, line.B)
+	dc.DrawStringAnchored(line.Label, horizontalMarginLeft+graphWidth+10, verticalMarginTop+30, 0.0, 0.0)
+	for i, band := range bands {
+		dc.SetRGB(band.R, band.G, band.B)
+		dc.DrawStringAnchored(band.Label, horizontalMarginLeft+graphWidth+10, verticalMarginTop+55+25*float64(i), 0.0, 0.0)
+	}
 
-	// Draw the marks and labels
+	dc.SavePNG(outputFileName)
+}
+
+// DrawHeatmap draws the counts of cells as colours, the columns from left to right and the rows of every column from the bottom up,
+// e.g. the schedules by elapsed period and by phase within the period. The colours scale with the logarithm of the count,
+// so that a few crowded cells don't hide the rest. A colour bar on the right shows the scale up to the maximum count.
+func DrawHeatmap(counts [][]int, maxCount int, topLabel, leftLabel, rightLabel string, outputFileName string) {
+	dc := newCanvas()
+
+	scale := func(count int) float64 {
+		return math.Log1p(float64(count)) / math.Log1p(float64(max(maxCount, 1)))
+	}
+	cellWidth := graphWidth / float64(max(len(counts), 1))
+	for x, column := range counts {
+		cellHeight := graphHeight / float64(max(len(column), 1))
+		for y, count := range column {
+			if count == 0 {
+				continue
+			}
+			dc.SetRGB(heatColor(scale(count)))
+			dc.DrawRectangle(horizontalMarginLeft+float64(x)*cellWidth, verticalMarginTop+graphHeight-float64(y+1)*cellHeight, cellWidth, cellHeight)
+			dc.Fill()
+		}
+	}
+
+	// Draw the colour bar, from a count of one to the maximum count
+	barLeft := horizontalMarginLeft + graphWidth + 15
+	lowest := scale(1)
+	for y := 0; y < int(graphHeight); y++ {
+		dc.SetRGB(heatColor(lowest + (1-lowest)*float64(y)/graphHeight))
+		dc.DrawRectangle(barLeft, verticalMarginTop+graphHeight-float64(y+1), 20, 1)
+		dc.Fill()
+	}
+
+	drawFrame(dc, topLabel, leftLabel, rightLabel)
+	dc.SetRGB(1, 1, 1)
+	dc.DrawStringAnchored(strconv.Itoa(maxCount), barLeft+25, verticalMarginTop+15, 0.0, 0.0)
+	dc.DrawStringAnchored("1", barLeft+25, verticalMarginTop+graphHeight, 0.0, 0.0)
+
+	dc.SavePNG(outputFileName)
+}
+
+// heatStops are the colours of a heatmap from the lowest to the highest value, from dark blue over green to yellow.
+var heatStops = [][3]float64{{0.15, 0.1, 0.4}, {0.1, 0.45, 0.55}, {0.2, 0.7, 0.35}, {1, 0.9, 0.15}}
+
+// heatColor interpolates the colour of a value between zero and one.
+func heatColor(val float64) (float64, float64, float64) {
+	pos := math.Max(0, math.Min(1, val)) * float64(len(heatStops)-1)
+	idx := min(int(pos), len(heatStops)-2)
+	f := pos - float64(idx)
+	from, to := heatStops[idx], heatStops[idx+1]
+	return from[0] + f*(to[0]-from[0]), from[1] + f*(to[1]-from[1]), from[2] + f*(to[2]-from[2])
+}
+
+// formatValue formats a label value with fewer decimals the larger it is, e.g. rates of a few events per second.
+func formatValue(val float64) string {
+	switch {
+	case val >= 100:
+		return fmt.Sprintf("%.0f", val)
+	case val >= 10:
+		return fmt.Sprintf("%.1f", val)
+	default:
+		return fmt.Sprintf("%.2f", val)
+	}
+}
+
+// newCanvas returns a drawing context with the background already set.
+func newCanvas() *gg.Context {
+	dc := gg.NewContext(int(graphWidth+horizontalMarginLeft+horizontalMarginRight), int(graphHeight+verticalMarginTop+verticalMarginBottom))
+
+	// Set the background
+	dc.SetRGB(0.1, 0.1, 0.1)
+	dc.Clear()
+	dc.Fill()
+	return dc
+}
+
+// drawFrame draws the marks and labels around the graph. It leaves the font face set for further labels.
+func drawFrame(dc *gg.Context, topLabel, leftLabel, rightLabel string) {
 	font, err := truetype.Parse(goregular.TTF)
 	if err != nil {
 		panic("font!")
@@ -55,10 +315,9 @@ func Draw(hist *histogram.Histogram, startLabel, endLabel string, outputFileName
 
 	dc.SetRGB(1, 0, 0)
 	//// Draw the top horizontal line and label
-	text := fmt.Sprintf("%d", hist.MaxHeight())
-	topLabelWidth, _ := dc.MeasureString(text)
+	topLabelWidth, _ := dc.MeasureString(topLabel)
 
-	dc.DrawStringAnchored(text, 10, 25, 0.0, 0.0)
+	dc.DrawStringAnchored(topLabel, 10, 25, 0.0, 0.0)
 	dc.DrawLine(10+(topLabelWidth+10), 15, horizontalMarginLeft+graphWidth, 15)
 
 	//// Draw the bottom horizontal line and label
@@ -67,15 +326,14 @@ func Draw(hist *histogram.Histogram, startLabel, endLabel string, outputFileName
 	dc.DrawLine(10+(bottomLabelWidth+10), graphHeight+verticalMarginTop, horizontalMarginLeft+graphWidth, graphHeight+verticalMarginTop)
 
 	//// Draw the left vertical line and label
-	leftLabelWidth, _ := dc.MeasureString(startLabel)
-	dc.DrawStringAnchored(startLabel, horizontalMarginLeft-leftLabelWidth/2, graphHeight+verticalMarginTop+30, 0.0, 0.0)
+	leftLabelWidth, _ := dc.MeasureString(leftLabel)
+	dc.DrawStringAnchored(leftLabel, horizontalMarginLeft-leftLabelWidth/2, graphHeight+verticalMarginTop+30, 0.0, 0.0)
 	dc.DrawLine(horizontalMarginLeft, graphHeight+verticalMarginTop, horizontalMarginLeft, graphHeight+verticalMarginTop+10)
 
 	//// Draw the right vertical line and label
-	rightLabelWidth, _ := dc.MeasureString(startLabel + "+" + endLabel)
-	dc.DrawStringAnchored(startLabel+"+"+endLabel, horizontalMarginLeft+graphWidth-rightLabelWidth/2, graphHeight+verticalMarginTop+30, 0.0, 0.0)
+	rightLabelWidth, _ := dc.MeasureString(rightLabel)
+	dc.DrawStringAnchored(rightLabel, horizontalMarginLeft+graphWidth-rightLabelWidth/2, graphHeight+verticalMarginTop+30, 0.0, 0.0)
 	dc.DrawLine(horizontalMarginLeft+graphWidth, graphHeight+verticalMarginTop, horizontalMarginLeft+graphWidth, graphHeight+verticalMarginTop+10)
 
 	dc.Stroke()
-	dc.SavePNG(outputFileName)
 }
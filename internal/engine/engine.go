@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"container/heap"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// Kind tells what happens at the time of an event.
+type Kind int
+
+const (
+	Due      Kind = iota // The object is due for reconciliation
+	Queued               // The object passed the rate limiters and waits for a worker
+	Finished             // A worker finished reconciling the object
+	Restart              // The controller restarts. Not related to a single object.
+	Watch                // A resource watched by the controller changed, and the object must be reconciled
+)
+
+type Event struct {
+	Time   float64 // Virtual time in milliseconds
+	Kind   Kind
+	Object *model.Object
+	Retry  bool   // Whether a Due event retries a failed reconciliation
+	seq    uint64 // Order of scheduling, breaks ties between events with the same time
+	gen    uint64 // Generation of the object when the event was scheduled
+}
+
+// Component reacts to the events of a simulation. It may schedule new events.
+type Component interface {
+	HandleEvent(e *Engine, ev Event)
+}
+
+// Engine is a discrete-event simulation engine.
+// It keeps a time-ordered queue of events across all objects and advances a virtual clock from one event to the next.
+// Every event is passed to all components, in the order they were added.
+type Engine struct {
+	now        float64
+	endTime    float64
+	seq        uint64
+	queue      eventQueue
+	components []Component
+	gens       map[*model.Object]uint64
+}
+
+// New creates an engine that simulates the time range [0, endTime), in milliseconds.
+func New(endTime float64) *Engine {
+	return &Engine{
+		endTime: endTime,
+		gens:    map[*model.Object]uint64{},
+	}
+}
+
+func (e *Engine) AddComponent(c Component) *Engine {
+	e.components = append(e.components, c)
+	return e
+}
+
+// Now returns the current virtual time in milliseconds.
+func (e *Engine) Now() float64 {
+	return e.now
+}
+
+func (e *Engine) EndTime() float64 {
+	return e.endTime
+}
+
+// Schedule adds an event to the queue. Events at or after the end time are never handled.
+func (e *Engine) Schedule(ev Event) {
+	if ev.Time < e.now {
+		panic("Event is scheduled in the past")
+	}
+	ev.seq = e.seq
+	e.seq++
+	if ev.Object != nil {
+		ev.gen = e.gens[ev.Object]
+	}
+	heap.Push(&e.queue, ev)
+}
+
+// Cancel drops the events of the object that are already scheduled.
+// Watch events come from outside the controller, so they are never dropped.
+func (e *Engine) Cancel(obj *model.Object) {
+	e.gens[obj]++
+}
+
+// ScheduleObjects schedules a Due event at the last schedule of every object.
+func (e *Engine) ScheduleObjects(objects model.ObjSet) {
+	for _, obj := range objects {
+		e.Schedule(Event{Time: obj.LastSchedule(), Kind: Due, Object: obj})
+	}
+}
+
+// scheduleDue schedules a Due event at the last schedule of the object.
+// The default jitter strategy may place a schedule before the current time late in a simulation, then it is due right away.
+func scheduleDue(e *Engine, obj *model.Object) {
+	e.Schedule(Event{Time: max(e.Now(), obj.LastSchedule()), Kind: Due, Object: obj})
+}
+
+// Run handles the events in time order, until the queue is empty or the end time is reached.
+func (e *Engine) Run() {
+	for e.queue.Len() > 0 && e.queue[0].Time < e.endTime {
+		ev := heap.Pop(&e.queue).(Event)
+		if ev.Object != nil && ev.Kind != Watch && ev.gen != e.gens[ev.Object] {
+			continue
+		}
+		e.now = ev.Time
+		for _, c := range e.components {
+			c.HandleEvent(e, ev)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	commonDelta = 0.00001
+)
+
+// recorder remembers the time and object ID of every handled event.
+type recorder struct {
+	times []float64
+	ids   []int
+}
+
+func (r *recorder) HandleEvent(e *Engine, ev Event) {
+	r.times = append(r.times, e.Now())
+	r.ids = append(r.ids, ev.Object.ID())
+}
+
+func TestRunHandlesEventsInTimeOrder(t *testing.T) {
+	rec := &recorder{}
+	e := New(100).AddComponent(rec)
+
+	e.Schedule(Event{Time: 30, Object: model.NewObject(1, 0, 0, 0)})
+	e.Schedule(Event{Time: 10, Object: model.NewObject(2, 0, 0, 0)})
+	e.Schedule(Event{Time: 30, Object: model.NewObject(3, 0, 0, 0)})
+	e.Schedule(Event{Time: 20, Object: model.NewObject(4, 0, 0, 0)})
+	e.Schedule(Event{Time: 100, Object: model.NewObject(5, 0, 0, 0)})
+	e.Run()
+
+	assert.Equal(t, []float64{10, 20, 30, 30}, rec.times)
+	assert.Equal(t, []int{2, 4, 1, 3}, rec.ids)
+}
+
+func TestPeriodicRequeue(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(30).SetRandomSupport(rs),
+		model.NewObject(2, 10, 0, 0).SetPeriod(50).SetRandomSupport(rs),
+	}
+	rec := &recorder{}
+	e := New(100).AddComponent(PeriodicRequeue{}).AddComponent(rec)
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	assert.Equal(t, []float64{0, 10, 30, 60, 60, 90}, rec.times)
+	assert.Equal(t, []int{1, 2, 1, 2, 1, 1}, rec.ids)
+
+	// Every object is scheduled until its last schedule passes the end time
+	assert.Equal(t, []float64{0, 30, 60, 90, 120}, objects[0].Schedules())
+	assert.Equal(t, []float64{10, 60, 110}, objects[1].Schedules())
+}
+
+func TestWorkerPoolQueuesObjects(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+		model.NewObject(2, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+		model.NewObject(3, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+	}
+	var reconciles []model.Reconcile
+	pool := NewWorkerPool(2, model.ConstantDistribution{Value: 10}, rs).OnReconcile(func(r model.Reconcile) {
+		reconciles = append(reconciles, r)
+	})
+	e := New(50).AddComponent(pool)
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10},
+		{ObjectID: 2, Due: 0, Start: 0, Finish: 10},
+		{ObjectID: 3, Due: 0, Start: 10, Finish: 20},
+	}, reconciles)
+
+	// The next schedule is one period after the reconciliation finished
+	assert.Equal(t, []float64{0, 110}, objects[0].Schedules())
+	assert.Equal(t, []float64{0, 120}, objects[2].Schedules())
+}
+
+func TestWatchEventsResetThePeriod(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(30).SetRandomSupport(rs),
+		model.NewObject(2, 10, 0, 0).SetPeriod(50).SetLifetime(0, 40).SetRandomSupport(rs),
+	}
+	e := New(100).AddComponent(PeriodicRequeue{})
+	e.ScheduleWatchEvents(objects, []model.WatchEvent{{Time: 45, ObjectID: 1}, {Time: 45, ObjectID: 2}, {Time: 50, ObjectID: 1}})
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	assert.Equal(t, []float64{0, 30, 45, 50, 80, 110}, objects[0].Schedules())
+	// Deleted objects ignore watch events
+	assert.Equal(t, []float64{10}, objects[1].Schedules())
+}
+
+func TestWorkerPoolDeduplicatesWatchEvents(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+		model.NewObject(2, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+	}
+	var reconciles []model.Reconcile
+	pool := NewWorkerPool(1, model.ConstantDistribution{Value: 10}, rs).OnReconcile(func(r model.Reconcile) {
+		reconciles = append(reconciles, r)
+	})
+	e := New(40).AddComponent(pool)
+	// Object 1 changes while it is reconciled, object 2 while it waits for a worker
+	e.ScheduleWatchEvents(objects, []model.WatchEvent{{Time: 5, ObjectID: 1}, {Time: 5, ObjectID: 2}})
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10},
+		{ObjectID: 2, Due: 0, Start: 10, Finish: 20},
+		{ObjectID: 1, Due: 10, Start: 20, Finish: 30},
+	}, reconciles)
+}
+
+func TestScheduleHistogramCountsDueObjects(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(30).SetRandomSupport(rs).DiscardHistory(),
+		model.NewObject(2, 10, 0, 0).SetPeriod(50).SetRandomSupport(rs).DiscardHistory(),
+	}
+	hist := NewScheduleHistogram(0, 50, 2)
+	e := New(200).AddComponent(PeriodicRequeue{}).AddComponent(hist)
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	// Schedules at 0, 10, 30 and 60, 60, 90; the later ones are outside of the histogram
+	assert.Equal(t, []int{3, 3}, hist.Schedules().Data())
+	assert.Equal(t, []int{0, 0}, hist.Retries().Data())
+	assert.Equal(t, []float64{210}, objects[0].Schedules())
+}
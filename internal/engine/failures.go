@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// Outage is a time window [Start, End), in milliseconds, in which reconciliations fail with a different probability.
+type Outage struct {
+	Start              float64
+	End                float64
+	FailureProbability float64
+}
+
+// FailureModel decides whether a reconciliation fails.
+type FailureModel struct {
+	failureProbability float64
+	outages            []Outage
+	rs                 model.RandomSupport
+}
+
+// NewFailureModel creates a model in which every reconciliation fails with the given probability, unless it starts during an outage.
+func NewFailureModel(failureProbability float64, outages []Outage, rs model.RandomSupport) *FailureModel {
+	return &FailureModel{
+		failureProbability: failureProbability,
+		outages:            outages,
+		rs:                 rs,
+	}
+}
+
+// FailureProbability returns the probability that a reconciliation started at the given time fails.
+func (m *FailureModel) FailureProbability(at float64) float64 {
+	for _, outage := range m.outages {
+		if at >= outage.Start && at < outage.End {
+			return outage.FailureProbability
+		}
+	}
+	return m.failureProbability
+}
+
+// Fails decides whether a reconciliation started at the given time fails.
+func (m *FailureModel) Fails(at float64) bool {
+	p := m.FailureProbability(at)
+	return p > 0 && m.rs.RandomlyDecide(p)
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureModelOutages(t *testing.T) {
+	m := NewFailureModel(0.01, []Outage{{Start: 100, End: 200, FailureProbability: 0.5}}, model.NewSeededRandomSupport(1))
+
+	assert.Equal(t, 0.01, m.FailureProbability(99))
+	assert.Equal(t, 0.5, m.FailureProbability(100))
+	assert.Equal(t, 0.5, m.FailureProbability(199))
+	assert.Equal(t, 0.01, m.FailureProbability(200))
+}
+
+func TestWorkerPoolRetriesFailures(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	obj := model.NewObject(1, 0, 0, 0).SetPeriod(1000).SetRandomSupport(rs)
+
+	var reconciles []model.Reconcile
+	failures := NewFailureModel(0, []Outage{{Start: 0, End: 50, FailureProbability: 1}}, rs)
+	pool := NewWorkerPool(1, model.ConstantDistribution{Value: 10}, rs).
+		SetRateLimiters(nil, NewItemBackoff(5, 1000)).
+		SetFailures(failures).
+		OnReconcile(func(r model.Reconcile) {
+			reconciles = append(reconciles, r)
+		})
+	e := New(100).AddComponent(pool)
+	e.ScheduleObjects(model.ObjSet{obj})
+	e.Run()
+
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10, Failed: true},
+		{ObjectID: 1, Due: 10, BackoffDelay: 5, Start: 15, Finish: 25, Retry: true, Failed: true},
+		{ObjectID: 1, Due: 25, BackoffDelay: 10, Start: 35, Finish: 45, Retry: true, Failed: true},
+		{ObjectID: 1, Due: 45, BackoffDelay: 20, Start: 65, Finish: 75, Retry: true},
+	}, reconciles)
+
+	// The backoff is reset after the success
+	assert.Equal(t, []float64{0, 10, 25, 45, 1075}, obj.Schedules())
+	assert.True(t, obj.IsRetry(3))
+	assert.False(t, obj.IsRetry(4))
+}
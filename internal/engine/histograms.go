@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+)
+
+// ScheduleHistogram adds the schedules of the objects to a histogram when they are due, so that they don't need to be stored.
+// Retries are also added to a second histogram with the same buckets.
+type ScheduleHistogram struct {
+	schedules *histogram.Histogram
+	retries   *histogram.Histogram
+}
+
+// NewScheduleHistogram creates the histograms for the time range [fromMillis, fromMillis+bucketWidthMillis*bucketCount).
+// Schedules outside of the range are ignored.
+func NewScheduleHistogram(fromMillis, bucketWidthMillis, bucketCount int) *ScheduleHistogram {
+	return &ScheduleHistogram{
+		schedules: histogram.NewHistogram(fromMillis, bucketWidthMillis, bucketCount),
+		retries:   histogram.NewHistogram(fromMillis, bucketWidthMillis, bucketCount),
+	}
+}
+
+func (h *ScheduleHistogram) HandleEvent(e *Engine, ev Event) {
+	if ev.Kind != Due || !h.schedules.Contains(int(ev.Time)) {
+		return
+	}
+	h.schedules.AddDataPoint(int(ev.Time))
+	if ev.Retry {
+		h.retries.AddDataPoint(int(ev.Time))
+	}
+}
+
+// Merge adds the schedules of the other histogram, e.g. built by another engine, which must have the same buckets.
+func (h *ScheduleHistogram) Merge(other *ScheduleHistogram) {
+	h.schedules.Merge(other.schedules)
+	h.retries.Merge(other.retries)
+}
+
+func (h *ScheduleHistogram) Schedules() *histogram.Histogram {
+	return h.schedules
+}
+
+func (h *ScheduleHistogram) Retries() *histogram.Histogram {
+	return h.retries
+}
@@ -0,0 +1,33 @@
+package engine
+
+// eventQueue is a priority queue of events, ordered by time.
+// Events with the same time are kept in the order they were scheduled, so that simulations stay deterministic.
+// It implements heap.Interface.
+type eventQueue []Event
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].Time == q[j].Time {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].Time < q[j].Time
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *eventQueue) Push(x any) {
+	*q = append(*q, x.(Event))
+}
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	ev := old[n-1]
+	*q = old[:n-1]
+	return ev
+}
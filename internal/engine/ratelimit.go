@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"math"
+)
+
+// TokenBucket models a client-go style token bucket rate limiter, shared by all objects.
+// The bucket holds up to burst tokens and is refilled with qps tokens per second. Every object takes one token.
+// If there is no token left, the object waits until a token would be available.
+type TokenBucket struct {
+	qps        float64
+	burst      float64
+	tokens     float64
+	lastUpdate float64
+}
+
+func NewTokenBucket(qps float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		qps:    qps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// When takes a token and returns how long the object has to wait for it, in milliseconds.
+func (b *TokenBucket) When(now float64) float64 {
+	b.tokens = math.Min(b.burst, b.tokens+(now-b.lastUpdate)*b.qps/1000)
+	b.lastUpdate = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return -b.tokens / b.qps * 1000
+}
+
+// Reset fills the bucket, like creating a new one.
+func (b *TokenBucket) Reset(now float64) {
+	b.tokens = b.burst
+	b.lastUpdate = now
+}
+
+// ItemBackoff models the client-go per-item exponential failure rate limiter.
+// Every time an object is rate limited, its delay doubles, starting at base and capped at max. Forgetting the object resets its delay.
+type ItemBackoff struct {
+	base     float64
+	max      float64
+	failures map[int]int
+}
+
+func NewItemBackoff(baseMillis, maxMillis float64) *ItemBackoff {
+	return &ItemBackoff{
+		base:     baseMillis,
+		max:      maxMillis,
+		failures: map[int]int{},
+	}
+}
+
+// When returns how long the object with the given ID has to wait, in milliseconds.
+func (b *ItemBackoff) When(id int) float64 {
+	exp := b.failures[id]
+	b.failures[id]++
+	return math.Min(b.max, b.base*math.Pow(2, float64(exp)))
+}
+
+// Forget resets the delay of the object with the given ID.
+func (b *ItemBackoff) Forget(id int) {
+	delete(b.failures, id)
+}
+
+// Reset forgets all objects.
+func (b *ItemBackoff) Reset() {
+	b.failures = map[int]int{}
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(10, 2)
+
+	// The burst is available immediately
+	assert.Equal(t, 0.0, b.When(0))
+	assert.Equal(t, 0.0, b.When(0))
+
+	// Afterwards, a token is added every 100ms
+	assert.InDelta(t, 100.0, b.When(0), commonDelta)
+	assert.InDelta(t, 200.0, b.When(0), commonDelta)
+	assert.InDelta(t, 150.0, b.When(150), commonDelta)
+
+	// The bucket never holds more than the burst
+	assert.Equal(t, 0.0, b.When(10000))
+	assert.Equal(t, 0.0, b.When(10000))
+	assert.InDelta(t, 100.0, b.When(10000), commonDelta)
+}
+
+func TestItemBackoff(t *testing.T) {
+	b := NewItemBackoff(5, 30)
+
+	assert.Equal(t, 5.0, b.When(1))
+	assert.Equal(t, 10.0, b.When(1))
+	assert.Equal(t, 5.0, b.When(2))
+	assert.Equal(t, 20.0, b.When(1))
+	assert.Equal(t, 30.0, b.When(1))
+
+	b.Forget(1)
+	assert.Equal(t, 5.0, b.When(1))
+}
+
+func TestWorkerPoolAppliesRateLimiters(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(1000).SetRandomSupport(rs),
+		model.NewObject(2, 0, 0, 0).SetPeriod(1000).SetRandomSupport(rs),
+	}
+	var reconciles []model.Reconcile
+	pool := NewWorkerPool(0, model.ConstantDistribution{Value: 10}, rs).
+		SetRateLimiters(NewTokenBucket(10, 1), NewItemBackoff(5, 1000)).
+		OnReconcile(func(r model.Reconcile) {
+			reconciles = append(reconciles, r)
+		})
+	e := New(1500).AddComponent(pool)
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	// The backoff only delays retries of failed reconciliations
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 1, Due: 0, BucketDelay: 0, Start: 0, Finish: 10},
+		{ObjectID: 2, Due: 0, BucketDelay: 100, Start: 100, Finish: 110},
+		{ObjectID: 1, Due: 1010, BucketDelay: 0, Start: 1010, Finish: 1020},
+		{ObjectID: 2, Due: 1110, BucketDelay: 0, Start: 1110, Finish: 1120},
+	}, reconciles)
+}
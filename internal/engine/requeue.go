@@ -0,0 +1,18 @@
+package engine
+
+// PeriodicRequeue is the simplest model of a controller: every object is requeued on its own, one jittered period after it was due,
+// until it is deleted. A watch event makes the object due right away, and its period starts again from there.
+type PeriodicRequeue struct{}
+
+func (PeriodicRequeue) HandleEvent(e *Engine, ev Event) {
+	if ev.Kind == Watch && ev.Object.Exists(e.Now()) {
+		trigger(e, ev.Object)
+		return
+	}
+	if ev.Kind != Due {
+		return
+	}
+	if ev.Object.AddRandomSchedule() {
+		scheduleDue(e, ev.Object)
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// Restarter models what happens to the objects when the controller restarts, e.g. when its pod is restarted or its informer resyncs.
+// All pending events of the existing and deleted objects are dropped, and every existing object is scheduled again according to the placement,
+// no matter what jitter it had built up.
+// Components that keep state, like the WorkerPool, reset it on the Restart event themselves.
+type Restarter struct {
+	objects   model.ObjSet
+	placement model.Placement
+	shard     map[*model.Object]bool
+}
+
+func NewRestarter(objects model.ObjSet, placement model.Placement) *Restarter {
+	return &Restarter{
+		objects:   objects,
+		placement: placement,
+	}
+}
+
+// Shard restricts the restarter to the given objects, e.g. the objects simulated by one of several engines.
+// They are placed as if all objects were restarted together.
+func (r *Restarter) Shard(objects model.ObjSet) *Restarter {
+	r.shard = make(map[*model.Object]bool, len(objects))
+	for _, obj := range objects {
+		r.shard[obj] = true
+	}
+	return r
+}
+
+func (r *Restarter) HandleEvent(e *Engine, ev Event) {
+	if ev.Kind != Restart {
+		return
+	}
+
+	// Deleted objects may still be queued or reconciled, so their events are dropped too.
+	// Objects created later keep their first schedule.
+	var existing model.ObjSet
+	for _, obj := range r.objects {
+		if (r.shard == nil || r.shard[obj]) && obj.Born() <= e.Now() {
+			e.Cancel(obj)
+		}
+		if obj.Exists(e.Now()) {
+			existing = append(existing, obj)
+		}
+	}
+
+	for idx, obj := range existing {
+		if r.shard != nil && !r.shard[obj] {
+			continue
+		}
+		if obj.RestartSchedule(e.Now(), r.placement, idx, len(existing)) {
+			e.Schedule(Event{Time: obj.LastSchedule(), Kind: Due, Object: obj})
+		}
+	}
+}
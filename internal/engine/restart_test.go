@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestarterRequeuesExistingObjects(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(30).SetRandomSupport(rs),
+		model.NewObject(2, 10, 0, 0).SetPeriod(50).SetRandomSupport(rs),
+		model.NewObject(3, 80, 0, 0).SetPeriod(50).SetLifetime(80, math.Inf(1)).SetRandomSupport(rs),
+		model.NewObject(4, 0, 0, 0).SetPeriod(50).SetLifetime(0, 40).SetRandomSupport(rs),
+	}
+	e := New(100).AddComponent(PeriodicRequeue{}).AddComponent(NewRestarter(objects, model.AllAtOncePlacement{}))
+	e.Schedule(Event{Time: 45, Kind: Restart})
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	// The pending schedules are dropped, and the periods start again at the restart
+	assert.Equal(t, []float64{0, 30, 45, 75, 105}, objects[0].Schedules())
+	assert.Equal(t, []float64{10, 45, 95, 145}, objects[1].Schedules())
+	// Objects created or deleted before the restart are not affected
+	assert.Equal(t, []float64{80, 130}, objects[2].Schedules())
+	assert.Equal(t, []float64{0}, objects[3].Schedules())
+}
+
+func TestWorkerPoolDropsQueueOnRestart(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+		model.NewObject(2, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+	}
+	var reconciles []model.Reconcile
+	pool := NewWorkerPool(1, model.ConstantDistribution{Value: 10}, rs).OnReconcile(func(r model.Reconcile) {
+		reconciles = append(reconciles, r)
+	})
+	e := New(30).AddComponent(pool).AddComponent(NewRestarter(objects, model.AllAtOncePlacement{}))
+	e.Schedule(Event{Time: 5, Kind: Restart})
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	// The reconciliation running during the restart never finishes, and both objects are queued again
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 1, Due: 5, Start: 5, Finish: 15},
+		{ObjectID: 2, Due: 5, Start: 15, Finish: 25},
+	}, reconciles)
+}
+
+func TestWorkerPoolDropsDeletedObjectsOnRestart(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(200).SetLifetime(0, 50).SetRandomSupport(rs),
+		model.NewObject(2, 70, 0, 0).SetPeriod(200).SetLifetime(70, math.Inf(1)).SetRandomSupport(rs),
+		model.NewObject(3, 70, 0, 0).SetPeriod(200).SetLifetime(70, math.Inf(1)).SetRandomSupport(rs),
+	}
+	var reconciles []model.Reconcile
+	pool := NewWorkerPool(1, model.ConstantDistribution{Value: 100}, rs).OnReconcile(func(r model.Reconcile) {
+		reconciles = append(reconciles, r)
+	})
+	e := New(300).AddComponent(pool).AddComponent(NewRestarter(objects, model.AllAtOncePlacement{}))
+	e.Schedule(Event{Time: 60, Kind: Restart})
+	e.ScheduleObjects(objects)
+	e.Run()
+
+	// The deleted object is running during the restart, and never finishes. The single worker still reconciles one object at a time
+	assert.Equal(t, []model.Reconcile{
+		{ObjectID: 2, Due: 70, Start: 70, Finish: 170},
+		{ObjectID: 3, Due: 70, Start: 170, Finish: 270},
+	}, reconciles)
+}
+
+func TestShardedRestarterPlacesAmongAllObjects(t *testing.T) {
+	rs := model.NewSeededRandomSupport(1)
+	objects := model.ObjSet{
+		model.NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+		model.NewObject(2, 0, 0, 0).SetPeriod(100).SetRandomSupport(rs),
+	}
+	e := New(60).AddComponent(PeriodicRequeue{}).AddComponent(NewRestarter(objects, model.LinearPlacement{}).Shard(objects[1:]))
+	e.Schedule(Event{Time: 10, Kind: Restart})
+	e.ScheduleObjects(objects[1:])
+	e.Run()
+
+	assert.Equal(t, []float64{0}, objects[0].Schedules())
+	assert.Equal(t, []float64{0, 60}, objects[1].Schedules())
+}
@@ -0,0 +1,27 @@
+package engine
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// ScheduleWatchEvents schedules a Watch event for every watch event of the objects. Events of unknown objects are ignored.
+func (e *Engine) ScheduleWatchEvents(objects model.ObjSet, events []model.WatchEvent) {
+	byID := make(map[int]*model.Object, len(objects))
+	for _, obj := range objects {
+		byID[obj.ID()] = obj
+	}
+	for _, ev := range events {
+		if obj, ok := byID[ev.ObjectID]; ok {
+			e.Schedule(Event{Time: ev.Time, Kind: Watch, Object: obj})
+		}
+	}
+}
+
+// trigger drops the pending events of the object, and makes it due right away.
+// The periodic schedule of the object starts again from now.
+func trigger(e *Engine, obj *model.Object) {
+	e.Cancel(obj)
+	if obj.Trigger(e.Now()) {
+		e.Schedule(Event{Time: e.Now(), Kind: Due, Object: obj})
+	}
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+// WorkerPool models a controller that reconciles due objects with a limited number of workers.
+// Due objects pass the optional rate limiters first, then wait in a FIFO queue until a worker is free. Every reconciliation takes a random processing time.
+// Once it is finished, the object is requeued one jittered period later.
+// If the reconciliation failed, the object is due again right away, and waits for the per-item exponential backoff.
+// Deleted objects are not requeued.
+// Like in a client-go workqueue, a watch event for an object that is already queued is dropped,
+// and an object that changes while it is reconciled is requeued right after the reconciliation.
+type WorkerPool struct {
+	workers        int // Zero means unlimited
+	processingTime model.Distribution
+	rs             model.RandomSupport
+	onReconcile    func(model.Reconcile)
+	bucket         *TokenBucket
+	backoff        *ItemBackoff
+	failures       *FailureModel
+
+	busy    int
+	limited map[*model.Object]model.Reconcile
+	waiting []job
+	running map[*model.Object]model.Reconcile
+	dirty   map[*model.Object]bool
+}
+
+// job is an object waiting for a free worker.
+type job struct {
+	obj       *model.Object
+	reconcile model.Reconcile
+}
+
+func NewWorkerPool(workers int, processingTime model.Distribution, rs model.RandomSupport) *WorkerPool {
+	return &WorkerPool{
+		workers:        workers,
+		processingTime: processingTime,
+		rs:             rs,
+		limited:        map[*model.Object]model.Reconcile{},
+		running:        map[*model.Object]model.Reconcile{},
+		dirty:          map[*model.Object]bool{},
+	}
+}
+
+// SetRateLimiters sets the rate limiters applied to due objects. Both are optional.
+// The token bucket applies to every due object, the backoff only to the retries of failed reconciliations.
+// Like with client-go AddRateLimited and the MaxOfRateLimiter, a retry waits for the longest delay of both rate limiters,
+// and the backoff of an object is forgotten once it is reconciled successfully.
+func (p *WorkerPool) SetRateLimiters(bucket *TokenBucket, backoff *ItemBackoff) *WorkerPool {
+	p.bucket = bucket
+	p.backoff = backoff
+	return p
+}
+
+// OnReconcile sets a function that is called with every finished reconciliation.
+func (p *WorkerPool) OnReconcile(f func(model.Reconcile)) *WorkerPool {
+	p.onReconcile = f
+	return p
+}
+
+// SetFailures sets the model that decides which reconciliations fail.
+// Without a backoff rate limiter, failed reconciliations are retried right away.
+func (p *WorkerPool) SetFailures(failures *FailureModel) *WorkerPool {
+	p.failures = failures
+	return p
+}
+
+func (p *WorkerPool) HandleEvent(e *Engine, ev Event) {
+	switch ev.Kind {
+	case Due:
+		r := model.Reconcile{ObjectID: ev.Object.ID(), Due: e.Now(), Retry: ev.Retry}
+		if p.bucket != nil {
+			r.BucketDelay = p.bucket.When(e.Now())
+		}
+		if p.backoff != nil && ev.Retry {
+			r.BackoffDelay = p.backoff.When(ev.Object.ID())
+		}
+		if r.Queued() > e.Now() {
+			p.limited[ev.Object] = r
+			e.Schedule(Event{Time: r.Queued(), Kind: Queued, Object: ev.Object})
+			return
+		}
+		p.waiting = append(p.waiting, job{obj: ev.Object, reconcile: r})
+	case Queued:
+		r, ok := p.limited[ev.Object]
+		if !ok { // Dropped by a restart
+			return
+		}
+		delete(p.limited, ev.Object)
+		p.waiting = append(p.waiting, job{obj: ev.Object, reconcile: r})
+	case Finished:
+		r, ok := p.running[ev.Object]
+		if !ok { // Dropped by a restart
+			return
+		}
+		delete(p.running, ev.Object)
+		p.busy--
+		r.Finish = e.Now()
+		r.Failed = p.failures != nil && p.failures.Fails(r.Start)
+		if p.onReconcile != nil {
+			p.onReconcile(r)
+		}
+		dirty := p.dirty[ev.Object]
+		delete(p.dirty, ev.Object)
+		if r.Failed {
+			if ev.Object.AddRetrySchedule(e.Now()) {
+				e.Schedule(Event{Time: e.Now(), Kind: Due, Object: ev.Object, Retry: true})
+			}
+			break
+		}
+		if p.backoff != nil {
+			p.backoff.Forget(ev.Object.ID())
+		}
+		if dirty {
+			trigger(e, ev.Object)
+		} else if ev.Object.AddRandomScheduleAfter(e.Now()) {
+			scheduleDue(e, ev.Object)
+		}
+	case Watch:
+		if !ev.Object.Exists(e.Now()) || p.isQueued(ev.Object) {
+			return
+		}
+		if _, ok := p.running[ev.Object]; ok {
+			p.dirty[ev.Object] = true
+			return
+		}
+		trigger(e, ev.Object)
+		return
+	case Restart:
+		p.reset(e.Now())
+	default:
+		return
+	}
+	p.startWaiting(e)
+}
+
+// reset drops all queued and running reconciliations, and resets the rate limiters, like a restarted controller.
+func (p *WorkerPool) reset(now float64) {
+	p.busy = 0
+	p.limited = map[*model.Object]model.Reconcile{}
+	p.waiting = nil
+	p.running = map[*model.Object]model.Reconcile{}
+	p.dirty = map[*model.Object]bool{}
+	if p.bucket != nil {
+		p.bucket.Reset(now)
+	}
+	if p.backoff != nil {
+		p.backoff.Reset()
+	}
+}
+
+// isQueued tells whether the object waits for the rate limiters or for a free worker.
+func (p *WorkerPool) isQueued(obj *model.Object) bool {
+	if _, ok := p.limited[obj]; ok {
+		return true
+	}
+	for _, j := range p.waiting {
+		if j.obj == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// startWaiting hands waiting objects to free workers.
+func (p *WorkerPool) startWaiting(e *Engine) {
+	for len(p.waiting) > 0 && (p.workers == 0 || p.busy < p.workers) {
+		j := p.waiting[0]
+		p.waiting = p.waiting[1:]
+
+		j.reconcile.Start = e.Now()
+		p.running[j.obj] = j.reconcile
+		p.busy++
+		e.Schedule(Event{Time: e.Now() + p.processingTime.Sample(p.rs), Kind: Finished, Object: j.obj})
+	}
+}
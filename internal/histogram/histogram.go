@@ -32,6 +32,11 @@ func (h *Histogram) BucketCount() int {
 	return h.bucketCount
 }
 
+// BucketWidth returns the time range of every bucket, in milliseconds.
+func (h *Histogram) BucketWidth() int {
+	return h.bucketWidth
+}
+
 func (h *Histogram) Data() []int {
 	return h.data
 }
@@ -41,6 +46,11 @@ func (h *Histogram) upperBound() int {
 	return h.fromTimeMillis + h.bucketWidth*h.bucketCount
 }
 
+// Contains tells whether the given timeMillis is in the histogram time range.
+func (h *Histogram) Contains(timeMillis int) bool {
+	return timeMillis >= h.fromTimeMillis && timeMillis < h.upperBound()
+}
+
 // getBucketIdx returns the index of the bucket that the given timeMillis belongs to.
 func (h *Histogram) getBucketIdx(timeMillis int) int {
 	if timeMillis < h.fromTimeMillis {
@@ -50,12 +60,7 @@ func (h *Histogram) getBucketIdx(timeMillis int) int {
 	if timeMillis >= h.upperBound() {
 		panic("Time is after the histogram range")
 	}
-	for i := 0; i < h.bucketCount-1; i++ {
-		if timeMillis < h.fromTimeMillis+h.bucketWidth*(i+1) {
-			return i
-		}
-	}
-	return h.bucketCount - 1
+	return (timeMillis - h.fromTimeMillis) / h.bucketWidth
 }
 
 func (h *Histogram) AddDataPoint(timeMillis int) {
@@ -73,3 +78,16 @@ func (h *Histogram) TotalCount() int {
 	}
 	return total
 }
+
+// Merge adds the data points of the other histogram, which must have the same time range and buckets.
+func (h *Histogram) Merge(other *Histogram) {
+	if other.fromTimeMillis != h.fromTimeMillis || other.bucketWidth != h.bucketWidth || other.bucketCount != h.bucketCount {
+		panic("Histograms have different buckets")
+	}
+	for idx, count := range other.data {
+		h.data[idx] += count
+		if h.data[idx] > h.maxHeight {
+			h.maxHeight = h.data[idx]
+		}
+	}
+}
@@ -60,3 +60,18 @@ func TestGetBucketIdxWithNonZeroStart(t *testing.T) {
 		assert.Equal(t, test.expectedBucketIdx, h.getBucketIdx(test.value))
 	}
 }
+
+func TestMerge(t *testing.T) {
+	h := NewHistogram(0, 100, 3)
+	h.AddDataPoint(50)
+	other := NewHistogram(0, 100, 3)
+	other.AddDataPoint(60)
+	other.AddDataPoint(250)
+
+	h.Merge(other)
+
+	assert.Equal(t, []int{2, 0, 1}, h.Data())
+	assert.Equal(t, 2, h.MaxHeight())
+	assert.True(t, h.Contains(299))
+	assert.False(t, h.Contains(300))
+}
@@ -0,0 +1,65 @@
+package model
+
+import "sort"
+
+// ArrivalProcess generates the creation times of objects, in milliseconds.
+type ArrivalProcess interface {
+	// Arrivals returns the creation times in the range [0, until), in ascending order.
+	Arrivals(until float64, rs RandomSupport) []float64
+}
+
+// PoissonArrivals creates objects at random, with exponentially distributed times between two creations.
+type PoissonArrivals struct {
+	PerSecond float64 // Average number of objects created per second
+}
+
+func (a PoissonArrivals) Arrivals(until float64, rs RandomSupport) []float64 {
+	var res []float64
+	interArrival := ExponentialDistribution{Mean: 1000 / a.PerSecond}
+	for t := interArrival.Sample(rs); t < until; t += interArrival.Sample(rs) {
+		res = append(res, t)
+	}
+	return res
+}
+
+// Burst creates Count objects, spread evenly over [At, At+Length).
+type Burst struct {
+	At     float64
+	Count  int
+	Length float64
+}
+
+// BurstArrivals creates objects in bursts, e.g. by a deployment pipeline.
+type BurstArrivals struct {
+	Bursts []Burst
+}
+
+func (a BurstArrivals) Arrivals(until float64, rs RandomSupport) []float64 {
+	var res []float64
+	for _, burst := range a.Bursts {
+		for i := 0; i < burst.Count; i++ {
+			t := burst.At + burst.Length*float64(i)/float64(burst.Count)
+			if t < until {
+				res = append(res, t)
+			}
+		}
+	}
+	sort.Float64s(res)
+	return res
+}
+
+// ReplayedArrivals creates objects at the given times, e.g. recorded in a real cluster.
+type ReplayedArrivals struct {
+	Times []float64
+}
+
+func (a ReplayedArrivals) Arrivals(until float64, rs RandomSupport) []float64 {
+	var res []float64
+	for _, t := range a.Times {
+		if t >= 0 && t < until {
+			res = append(res, t)
+		}
+	}
+	sort.Float64s(res)
+	return res
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoissonArrivals(t *testing.T) {
+	arrivals := PoissonArrivals{PerSecond: 10}.Arrivals(1000*1000, NewSeededRandomSupport(1))
+
+	assert.InDelta(t, 10000, len(arrivals), 500)
+	for i := 1; i < len(arrivals); i++ {
+		assert.Less(t, arrivals[i-1], arrivals[i])
+	}
+	assert.Less(t, arrivals[len(arrivals)-1], 1000*1000.0)
+}
+
+func TestBurstArrivals(t *testing.T) {
+	arrivals := BurstArrivals{Bursts: []Burst{
+		{At: 1000, Count: 4, Length: 100},
+		{At: 0, Count: 2, Length: 0},
+		{At: 5000, Count: 2, Length: 10},
+	}}.Arrivals(5005, RandomSupport{})
+
+	assert.Equal(t, []float64{0, 0, 1000, 1025, 1050, 1075, 5000}, arrivals)
+}
+
+func TestReplayedArrivals(t *testing.T) {
+	arrivals := ReplayedArrivals{Times: []float64{300, -1, 100, 200, 1000}}.Arrivals(1000, RandomSupport{})
+
+	assert.Equal(t, []float64{100, 200, 300}, arrivals)
+}
@@ -16,18 +16,23 @@ func TestAsCSVString(t *testing.T) {
 	}{
 		{
 			name:     "Object with one schedule",
-			obj:      NewObject(1, 0, 0),
-			expected: "1,0",
+			obj:      NewObject(1, 0, 0, 0),
+			expected: "1,300000,0,,0",
 		},
 		{
 			name:     "Object with two schedules",
-			obj:      NewObject(1, 2, 0).addSchedule(3),
-			expected: "1,2,3",
+			obj:      NewObject(1, 2, 0, 0).addSchedule(3),
+			expected: "1,300000,0,,2,3",
+		},
+		{
+			name:     "Object with a custom period",
+			obj:      NewObject(1, 2, 0, 0).SetPeriod(30000).addSchedule(3),
+			expected: "1,30000,0,,2,3",
 		},
 		{
 			name:     "Object with multiple schedules",
-			obj:      NewObject(1, 2, 0).addSchedule(3.4).addSchedule(5.6),
-			expected: "1,2,3.4,5.6",
+			obj:      NewObject(1, 2, 0, 0).addSchedule(3.4).addSchedule(5.6),
+			expected: "1,300000,0,,2,3.4,5.6",
 		},
 	}
 
@@ -51,27 +56,27 @@ func TestMarshal(t *testing.T) {
 		{
 			name:     "Empty list",
 			objects:  ObjSet{},
-			expected: "",
+			expected: "#format=2\n",
 		},
 		{
 			name:     "Single object",
-			objects:  ObjSet{NewObject(1, 0, 0)},
-			expected: "1,0\n",
+			objects:  ObjSet{NewObject(1, 0, 0, 0)},
+			expected: "#format=2\n1,300000,0,,0\n",
 		},
 		{
 			name: "Single object with two schedules",
 			objects: ObjSet{
-				NewObject(1, 2, 0).addSchedule(3),
+				NewObject(1, 2, 0, 0).addSchedule(3),
 			},
-			expected: "1,2,3\n",
+			expected: "#format=2\n1,300000,0,,2,3\n",
 		},
 		{
 			name: "Multiple objects",
 			objects: ObjSet{
-				NewObject(1, 0, 0),
-				NewObject(2, 3, 0).addSchedule(4),
+				NewObject(1, 0, 0, 0),
+				NewObject(2, 3, 0, 0).addSchedule(4),
 			},
-			expected: "1,0\n2,3,4\n",
+			expected: "#format=2\n1,300000,0,,0\n2,300000,0,,3,4\n",
 		},
 	}
 
@@ -99,25 +104,25 @@ func TestUnmarshalObjSet(t *testing.T) {
 	}{
 		{
 			name:     "Empty list",
-			data:     "",
+			data:     "#format=2\n",
 			expected: ObjSet{},
 		},
 		{
 			name:     "Single object",
-			data:     "1,0\n",
-			expected: ObjSet{NewObject(1, 0, 0)},
+			data:     "#format=2\n1,300000,0,,0\n",
+			expected: ObjSet{NewObject(1, 0, 0, 0)},
 		},
 		{
 			name:     "Single object with two schedules",
-			data:     "1,2,3\n",
-			expected: ObjSet{NewObject(1, 2, 0).addSchedule(3)},
+			data:     "#format=2\n1,300000,0,,2,3\n",
+			expected: ObjSet{NewObject(1, 2, 0, 0).addSchedule(3)},
 		},
 		{
 			name: "Multiple objects",
-			data: "1,0\n2,3,4\n",
+			data: "#format=2\n1,300000,0,,0\n2,300000,0,,3,4\n",
 			expected: ObjSet{
-				NewObject(1, 0, 0),
-				NewObject(2, 3, 0).addSchedule(4),
+				NewObject(1, 0, 0, 0),
+				NewObject(2, 3, 0, 0).addSchedule(4),
 			},
 		},
 	}
@@ -134,14 +139,58 @@ func TestUnmarshalObjSet(t *testing.T) {
 	}
 }
 
+func TestUnmarshalRejectsOtherFormats(t *testing.T) {
+	// Written before the period was stored, so the first schedule would be read as the period
+	_, err := UnmarshalObjSet(bytes.NewBufferString("1,0,300000\n"))
+	assert.Error(t, err)
+	_, err = UnmarshalObjSet(bytes.NewBufferString(""))
+	assert.Error(t, err)
+	_, err = UnmarshalObjSet(bytes.NewBufferString("#format=3\n1,300000,0,,0\n"))
+	assert.Error(t, err)
+}
+
+func TestRetrySchedules(t *testing.T) {
+	obj := NewObject(1, 2, 0, 0).addSchedule(3)
+	obj.AddRetrySchedule(3.5)
+	obj.addSchedule(4)
+	obj.AddRetrySchedule(4.5)
+
+	assert.Equal(t, "1,300000,0,,2,3,3.5r,4,4.5r", obj.asCSVString())
+	assert.False(t, obj.IsRetry(1))
+	assert.True(t, obj.IsRetry(2))
+	assert.False(t, obj.IsRetry(3))
+	assert.True(t, obj.IsRetry(4))
+
+	actual, err := fromCSVString(obj.asCSVString())
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{2, 3, 3.5, 4, 4.5}, actual.Schedules())
+	assert.True(t, actual.IsRetry(2))
+	assert.True(t, actual.IsRetry(4))
+}
+
+func TestLifetime(t *testing.T) {
+	obj := NewObject(1, 1000, 0, 0).SetPeriod(1000).SetLifetime(1000, 2500).SetRandomSupport(constantRandomSupport(0.5))
+	assert.Equal(t, "1,1000,1000,2500,1000", obj.asCSVString())
+
+	assert.True(t, obj.AddRandomSchedule())
+	assert.False(t, obj.AddRandomSchedule())
+	assert.False(t, obj.AddRetrySchedule(2500))
+	assert.Equal(t, []float64{1000, 2000}, obj.Schedules())
+
+	actual, err := fromCSVString(obj.asCSVString())
+	assert.Nil(t, err)
+	assert.Equal(t, 1000.0, actual.Born())
+	assert.Equal(t, 2500.0, actual.Died())
+}
+
 // Test if serialization and then deserilization yields the same object set
 func TestSerDeser(t *testing.T) {
 	initial := ObjSet{
-		NewObject(1, 0, 0),
-		NewObject(2, 3, 0).addSchedule(4),
-		NewObject(5, 6, 0).addSchedule(7).addSchedule(8),
-		NewObject(9, 10, 0).addSchedule(11).addSchedule(12).addSchedule(13),
-		NewObject(14, 15, 0),
+		NewObject(1, 0, 0, 0),
+		NewObject(2, 3, 0, 0).addSchedule(4),
+		NewObject(5, 6, 0, 0).addSchedule(7).addSchedule(8),
+		NewObject(9, 10, 0, 0).addSchedule(11).addSchedule(12).addSchedule(13),
+		NewObject(14, 15, 0, 0),
 	}
 
 	pseudoFile := &bytes.Buffer{}
@@ -153,3 +202,73 @@ func TestSerDeser(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, initial, actual)
 }
+
+func TestParamsSerDeser(t *testing.T) {
+	params := Params{}
+	params.SetFloat("jitter-probability", 0.02)
+	params.SetFloat("jitter-magnitude", 0.1)
+	objects := ObjSet{
+		NewObject(1, 0, 0, 0),
+		NewObject(2, 3, 0, 0).addSchedule(4),
+	}
+
+	pseudoFile := &bytes.Buffer{}
+	assert.Nil(t, params.Marshal(pseudoFile))
+	assert.Nil(t, objects.Marshal(pseudoFile))
+	assert.Equal(t, "#jitter-magnitude=0.1\n#jitter-probability=0.02\n#format=2\n1,300000,0,,0\n2,300000,0,,3,4\n", pseudoFile.String())
+
+	actualParams, actualObjects, err := Unmarshal(pseudoFile)
+	assert.Nil(t, err)
+	assert.Equal(t, params, actualParams)
+	assert.Equal(t, objects, actualObjects)
+
+	probability, ok := actualParams.Float("jitter-probability")
+	assert.True(t, ok)
+	assert.Equal(t, 0.02, probability)
+}
+
+func TestExpectedSchedules(t *testing.T) {
+	objects := ObjSet{
+		NewObject(1, 0, 0, 0).SetPeriod(30 * 1000),
+		NewObject(2, 0, 0, 0).SetPeriod(10 * 60 * 1000),
+		NewObject(3, 0, 0, 0).SetPeriod(60 * 60 * 1000),
+	}
+	assert.InDelta(t, 120.0+6.0+1.0, objects.ExpectedSchedules(0, 60*60*1000), commonDelta)
+
+	// Only the time when an object exists counts
+	objects[0].SetLifetime(30*60*1000, 90*60*1000)
+	assert.InDelta(t, 60.0+6.0+1.0, objects.ExpectedSchedules(0, 60*60*1000), commonDelta)
+	assert.InDelta(t, 0.0+6.0+1.0, objects.ExpectedSchedules(90*60*1000, 60*60*1000), commonDelta)
+}
+
+func TestReconcilesSerDeser(t *testing.T) {
+	reconciles := []Reconcile{
+		{ObjectID: 1, Due: 0, Start: 0, Finish: 10.5},
+		{ObjectID: 2, Due: 3, BucketDelay: 1, BackoffDelay: 2, Start: 10.5, Finish: 20, Retry: true, Failed: true},
+	}
+
+	pseudoFile := &bytes.Buffer{}
+	rw := NewReconcileWriter(pseudoFile)
+	for _, r := range reconciles {
+		assert.Nil(t, rw.Write(r))
+	}
+	assert.Nil(t, rw.Flush())
+	assert.Equal(t, "object,due,bucket-delay,backoff-delay,start,finish,retry,failed\n1,0,0,0,0,10.5,0,0\n2,3,1,2,10.5,20,1,1\n", pseudoFile.String())
+
+	actual, err := UnmarshalReconciles(pseudoFile)
+	assert.Nil(t, err)
+	assert.Equal(t, reconciles, actual)
+	assert.Equal(t, 5.0, actual[1].Queued())
+	assert.Equal(t, 5.5, actual[1].Wait())
+}
+
+func TestDiscardHistory(t *testing.T) {
+	obj := NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(constantRandomSupport(0.5)).DiscardHistory()
+	obj.AddRandomSchedule()
+	obj.AddRetrySchedule(150)
+	assert.Equal(t, []float64{150}, obj.Schedules())
+	assert.False(t, obj.IsRetry(0))
+
+	assert.True(t, obj.Trigger(120))
+	assert.Equal(t, []float64{120}, obj.Schedules())
+}
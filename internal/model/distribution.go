@@ -0,0 +1,36 @@
+package model
+
+import "math"
+
+// Distribution draws random durations, in milliseconds.
+type Distribution interface {
+	Sample(rs RandomSupport) float64
+}
+
+// ConstantDistribution always returns the same value.
+type ConstantDistribution struct {
+	Value float64
+}
+
+func (d ConstantDistribution) Sample(rs RandomSupport) float64 {
+	return d.Value
+}
+
+// UniformDistribution returns values uniformly distributed in [Min, Max).
+type UniformDistribution struct {
+	Min float64
+	Max float64
+}
+
+func (d UniformDistribution) Sample(rs RandomSupport) float64 {
+	return rs.RandomlyBetween(d.Min, d.Max)
+}
+
+// ExponentialDistribution returns exponentially distributed values with the given mean.
+type ExponentialDistribution struct {
+	Mean float64
+}
+
+func (d ExponentialDistribution) Sample(rs RandomSupport) float64 {
+	return -math.Log(1-rs.Float64()) * d.Mean
+}
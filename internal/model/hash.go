@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/pkg/jitter"
+)
+
+// HashFunction maps an object ID to a number, from which HashPlacement and HashSpreading derive the offset of the object within its period.
+type HashFunction func(id int) uint64
+
+// FNV1aHash hashes the decimal representation of the ID with jitter.FNV1a. This is the default.
+func FNV1aHash(id int) uint64 {
+	return jitter.FNV1a(strconv.Itoa(id))
+}
+
+// FNV1Hash hashes the decimal representation of the ID with jitter.FNV1.
+func FNV1Hash(id int) uint64 {
+	return jitter.FNV1(strconv.Itoa(id))
+}
+
+// CRC32Hash hashes the decimal representation of the ID with jitter.CRC32.
+func CRC32Hash(id int) uint64 {
+	return jitter.CRC32(strconv.Itoa(id))
+}
+
+// SHA256Hash hashes the decimal representation of the ID with jitter.SHA256.
+func SHA256Hash(id int) uint64 {
+	return jitter.SHA256(strconv.Itoa(id))
+}
+
+// IdentityHash uses the ID itself, so consecutive IDs get consecutive offsets of one millisecond each.
+// It shows how a poor hash clusters the objects.
+func IdentityHash(id int) uint64 {
+	return uint64(id)
+}
+
+// HashFunctionNames lists the names accepted by NewHashFunction.
+var HashFunctionNames = []string{"fnv1a", "fnv1", "crc32", "sha256", "identity"}
+
+// NewHashFunction returns the hash function with the given name.
+func NewHashFunction(name string) (HashFunction, error) {
+	switch name {
+	case "fnv1a":
+		return FNV1aHash, nil
+	case "fnv1":
+		return FNV1Hash, nil
+	case "crc32":
+		return CRC32Hash, nil
+	case "sha256":
+		return SHA256Hash, nil
+	case "identity":
+		return IdentityHash, nil
+	}
+	return nil, fmt.Errorf("unknown hash function: %s", name)
+}
+
+// hashOffset returns the offset of the object within its period with jitter.HashOffset, in whole milliseconds.
+// A nil hash function means FNV1aHash.
+func hashOffset(o *Object, hash HashFunction) float64 {
+	if hash == nil {
+		hash = FNV1aHash
+	}
+	byID := func(string) uint64 {
+		return hash(o.id)
+	}
+	return durationToMillis(jitter.HashOffset("", millisToDuration(o.period), byID))
+}
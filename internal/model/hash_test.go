@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashFunctions(t *testing.T) {
+	for _, name := range HashFunctionNames {
+		t.Run(name, func(t *testing.T) {
+			hash, err := NewHashFunction(name)
+			assert.Nil(t, err)
+			assert.Equal(t, hash(42), hash(42))
+			assert.NotEqual(t, hash(42), hash(43))
+		})
+	}
+
+	_, err := NewHashFunction("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestHashPlacementUsesHashFunction(t *testing.T) {
+	obj := NewObject(1234, 0, 0, 0).SetPeriod(1000)
+
+	assert.Equal(t, 234.0, HashPlacement{Hash: IdentityHash}.Offset(obj, 0, 1))
+	assert.Equal(t, HashPlacement{Hash: FNV1aHash}.Offset(obj, 0, 1), HashPlacement{}.Offset(obj, 0, 1))
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/pkg/jitter"
+)
+
+// JitterStrategy decides how long an object waits between two consecutive schedules.
+type JitterStrategy interface {
+	// NextInterval returns the time in milliseconds from the given time, usually the last schedule of the object, to the next schedule.
+	NextInterval(o *Object, from float64) float64
+}
+
+// scheduler is implemented by strategies that decide the next schedule itself rather than the interval to it,
+// so that adding the interval to the given time doesn't round it.
+type scheduler interface {
+	nextSchedule(o *Object, from float64) float64
+}
+
+// ProbabilisticJitter is the original model of the simulator: for jitterProbability of the schedules, it changes the time
+// one period after the given time by up to ±jitterMagnitude of that time. As the change is relative to the absolute time,
+// it grows as the simulation goes on, and late schedules may even be earlier than the previous one.
+// This is the default strategy. It draws the same random numbers in the same order as the original model.
+type ProbabilisticJitter struct{}
+
+func (s ProbabilisticJitter) NextInterval(o *Object, from float64) float64 {
+	return s.nextSchedule(o, from) - from
+}
+
+func (ProbabilisticJitter) nextSchedule(o *Object, from float64) float64 {
+	next := from + o.period
+	if o.rs.RandomlyDecide(o.jitterProbability) {
+		next = o.rs.RandomlyChange(next, o.jitterMagnitude)
+	}
+	return next
+}
+
+// The other strategies adapt those of the jitter package to the objects: they take the jitter parameters of the object,
+// and convert between milliseconds and durations.
+
+// ProbabilisticPeriodJitter changes the period by up to ±jitterMagnitude, but only for jitterProbability of the schedules.
+type ProbabilisticPeriodJitter struct{}
+
+func (ProbabilisticPeriodJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Probabilistic{Probability: o.jitterProbability, Magnitude: o.jitterMagnitude}, o, from)
+}
+
+// UniformJitter always changes the period by up to ±jitterMagnitude.
+type UniformJitter struct{}
+
+func (UniformJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Uniform{Magnitude: o.jitterMagnitude}, o, from)
+}
+
+// FullJitter picks the interval uniformly from [0, period).
+type FullJitter struct{}
+
+func (FullJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Full{}, o, from)
+}
+
+// EqualJitter keeps half of the period and picks the other half uniformly, so the interval is in [period/2, period).
+type EqualJitter struct{}
+
+func (EqualJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Equal{}, o, from)
+}
+
+// DecorrelatedJitter picks the interval uniformly between the period and three times the previous interval.
+// The result is capped at two periods.
+type DecorrelatedJitter struct{}
+
+func (DecorrelatedJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Decorrelated{}, o, from)
+}
+
+// GaussianJitter draws the interval from a normal distribution centered at the period, with a standard deviation of jitterMagnitude of the period.
+// The result is clamped to [0, 2*period], so that the mean stays at the period.
+type GaussianJitter struct{}
+
+func (GaussianJitter) NextInterval(o *Object, from float64) float64 {
+	return nextInterval(jitter.Gaussian{Sigma: o.jitterMagnitude}, o, from)
+}
+
+// HashSpreading schedules every object at a fixed offset within its period, derived from a hash of the object ID like HashPlacement.
+// The next schedule is on the grid of these offsets, at the point closest to one period after the given time,
+// so objects placed at once are spread after one period without any randomness.
+// With jitterProbability, the schedule is moved by up to ±jitterMagnitude of the period. The jitter doesn't add up,
+// because every schedule is placed on the grid again. The default hash function is FNV1aHash.
+type HashSpreading struct {
+	Hash HashFunction
+}
+
+func (s HashSpreading) NextInterval(o *Object, from float64) float64 {
+	hash := s.Hash
+	if hash == nil {
+		hash = FNV1aHash
+	}
+	// The key of a simulated object is its ID
+	byID := func(string) uint64 {
+		return hash(o.id)
+	}
+	return nextInterval(jitter.Hash{Func: byID, Probability: o.jitterProbability, Magnitude: o.jitterMagnitude}, o, from)
+}
+
+// nextInterval returns the interval of the strategy from the given time, in milliseconds.
+// The simulated time starts at the Unix epoch.
+func nextInterval(strategy jitter.Strategy, o *Object, from float64) float64 {
+	req := jitter.Request{
+		Now:      time.UnixMilli(0).Add(millisToDuration(from)),
+		Previous: millisToDuration(o.lastInterval),
+	}
+	return durationToMillis(strategy.Interval(millisToDuration(o.period), req, jitter.RandomFunc(o.rs.Float64)))
+}
+
+func millisToDuration(millis float64) time.Duration {
+	return time.Duration(millis * float64(time.Millisecond))
+}
+
+func durationToMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// JitterStrategyNames lists the names accepted by NewJitterStrategy.
+var JitterStrategyNames = []string{"probabilistic", "probabilistic-period", "uniform", "full", "equal", "decorrelated", "gaussian", "hash"}
+
+// NewJitterStrategy returns the strategy with the given name. The hash function is used by the hash strategy, nil means the default.
+func NewJitterStrategy(name string, hash HashFunction) (JitterStrategy, error) {
+	switch name {
+	case "probabilistic":
+		return ProbabilisticJitter{}, nil
+	case "probabilistic-period":
+		return ProbabilisticPeriodJitter{}, nil
+	case "uniform":
+		return UniformJitter{}, nil
+	case "full":
+		return FullJitter{}, nil
+	case "equal":
+		return EqualJitter{}, nil
+	case "decorrelated":
+		return DecorrelatedJitter{}, nil
+	case "gaussian":
+		return GaussianJitter{}, nil
+	case "hash":
+		return HashSpreading{Hash: hash}, nil
+	}
+	return nil, fmt.Errorf("unknown jitter strategy: %s", name)
+}
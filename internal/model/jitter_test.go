@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func constantRandomSupport(val float64) RandomSupport {
+	return RandomSupport{
+		Float64: func() float64 {
+			return val
+		},
+	}
+}
+
+func TestJitterStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy JitterStrategy
+		random   float64
+		expected float64
+	}{
+		{"probabilistic, not jittered", ProbabilisticJitter{}, 0.5, DefaultPeriod},
+		{"probabilistic, jittered", ProbabilisticJitter{}, 0.0, DefaultPeriod * 1.1},
+		{"probabilistic period, not jittered", ProbabilisticPeriodJitter{}, 0.5, DefaultPeriod},
+		{"probabilistic period, jittered", ProbabilisticPeriodJitter{}, 0.0, DefaultPeriod * 1.1},
+		{"uniform", UniformJitter{}, 0.5, DefaultPeriod},
+		{"uniform, lowest", UniformJitter{}, 1.0, DefaultPeriod * 0.9},
+		{"full", FullJitter{}, 0.25, DefaultPeriod / 4},
+		{"equal", EqualJitter{}, 0.5, DefaultPeriod * 0.75},
+		{"decorrelated", DecorrelatedJitter{}, 0.25, DefaultPeriod * 1.5},
+		{"decorrelated, capped", DecorrelatedJitter{}, 0.75, DefaultPeriod * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := NewObject(1, 0, 0.1, 0.1).SetRandomSupport(constantRandomSupport(tt.random))
+			assert.InDelta(t, tt.expected, tt.strategy.NextInterval(obj, 0), commonDelta)
+		})
+	}
+}
+
+func TestProbabilisticJitterIsTheOriginalModel(t *testing.T) {
+	// Generated by the original Object.AddRandomSchedule with a spread percent of 0.5, from the same random numbers
+	expected := []float64{300000, 354025.2371864987, 654025.2371864987, 954025.2371864987, 686154.0848240397, 986154.0848240397, 1.2861540848240396e+06}
+
+	obj := NewObject(1, DefaultPeriod, 0.5, 0.5).SetRandomSupport(NewSeededRandomSupport(1))
+	for len(obj.Schedules()) < len(expected) {
+		obj.AddRandomSchedule()
+	}
+	assert.Equal(t, expected, obj.Schedules())
+}
+
+func TestProbabilisticJitterChangesTheTime(t *testing.T) {
+	obj := NewObject(1, 0, 1, 0.1).SetRandomSupport(constantRandomSupport(0))
+	assert.InDelta(t, DefaultPeriod*1.1, ProbabilisticJitter{}.NextInterval(obj, 0), commonDelta)
+	// The change is relative to the time of the next schedule, not to the period
+	assert.InDelta(t, DefaultPeriod+10*DefaultPeriod*0.1, ProbabilisticJitter{}.NextInterval(obj, 9*DefaultPeriod), commonDelta)
+	assert.InDelta(t, DefaultPeriod*1.1, ProbabilisticPeriodJitter{}.NextInterval(obj, 9*DefaultPeriod), commonDelta)
+}
+
+func TestGaussianJitterIsClamped(t *testing.T) {
+	obj := NewObject(1, 0, 1, 10).SetRandomSupport(constantRandomSupport(0.999999))
+	interval := GaussianJitter{}.NextInterval(obj, 0)
+	assert.GreaterOrEqual(t, interval, 0.0)
+	assert.LessOrEqual(t, interval, 2*DefaultPeriod)
+}
+
+func TestAddRandomScheduleUsesStrategy(t *testing.T) {
+	obj := NewObject(1, 100, 0.1, 0.1).SetRandomSupport(constantRandomSupport(0.5)).SetJitterStrategy(EqualJitter{})
+	obj.AddRandomSchedule()
+	obj.AddRandomSchedule()
+	assert.Equal(t, []float64{100, 100 + DefaultPeriod*0.75, 100 + DefaultPeriod*1.5}, obj.Schedules())
+}
+
+func TestNewJitterStrategy(t *testing.T) {
+	for _, name := range JitterStrategyNames {
+		strategy, err := NewJitterStrategy(name, nil)
+		assert.Nil(t, err)
+		assert.NotNil(t, strategy)
+	}
+
+	_, err := NewJitterStrategy("unknown", nil)
+	assert.NotNil(t, err)
+}
+
+func TestHashSpreading(t *testing.T) {
+	strategy := HashSpreading{Hash: IdentityHash}
+	obj := NewObject(7, 0, 0, 0).SetPeriod(1000).SetRandomSupport(constantRandomSupport(0.5))
+
+	assert.Equal(t, 1000.0, strategy.NextInterval(obj, 7))
+	assert.Equal(t, 700.0, strategy.NextInterval(obj, 307))
+	assert.Equal(t, 1400.0, strategy.NextInterval(obj, 607))
+
+	// The jitter of a schedule doesn't move the next one
+	jittered := NewObject(7, 0, 1, 0.1).SetPeriod(1000).SetRandomSupport(constantRandomSupport(0))
+	assert.InDelta(t, 1100.0, strategy.NextInterval(jittered, 7), commonDelta)
+	assert.InDelta(t, 1000.0, strategy.NextInterval(jittered, 1107), commonDelta)
+}
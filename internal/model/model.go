@@ -2,27 +2,48 @@ package model
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 const (
-	AverageScheduleTime float64 = 5 * 60 * 1000 // 5 minutes in milliseconds
+	DefaultPeriod float64 = 5 * 60 * 1000 // 5 minutes in milliseconds
+
+	retrySuffix = "r" // Marks retry schedules in the CSV format
+
+	// The CSV format is written as a parameter, so that files with another layout of the objects are rejected instead of misread.
+	// Files without it store only the object ID and the schedules.
+	formatParam = "format"
+	csvFormat   = "2"
 )
 
 type Object struct {
-	id            int
-	schedule      []float64
-	spreadPercent float64
-	rs            RandomSupport
+	id                int
+	schedule          []float64
+	retries           []int   // Indexes of the schedules that are retries of a failed reconciliation, in ascending order
+	period            float64 // The base time between two schedules, in milliseconds
+	born              float64 // When the object was created, in milliseconds
+	died              float64 // When the object was deleted, in milliseconds. Infinity if it is never deleted.
+	jitterProbability float64 // How likely a schedule is jittered at all
+	jitterMagnitude   float64 // How much a jittered schedule moves, as a fraction of the period
+	lastInterval      float64
+	strategy          JitterStrategy
+	rs                RandomSupport
+	discardHistory    bool // Only the last schedule is kept
 }
 
-func NewObject(id int, initialSchedule float64, spreadPercent float64) *Object {
+func NewObject(id int, initialSchedule float64, jitterProbability float64, jitterMagnitude float64) *Object {
 	return &Object{
-		id:            id,
-		schedule:      []float64{initialSchedule},
-		spreadPercent: spreadPercent,
+		id:                id,
+		schedule:          []float64{initialSchedule},
+		period:            DefaultPeriod,
+		died:              math.Inf(1),
+		jitterProbability: jitterProbability,
+		jitterMagnitude:   jitterMagnitude,
 	}
 }
 
@@ -31,22 +52,146 @@ func (o *Object) SetRandomSupport(rs RandomSupport) *Object {
 	return o
 }
 
+func (o *Object) ID() int {
+	return o.id
+}
+
+// DiscardHistory makes the object keep only its last schedule, e.g. when the schedules are observed while the simulation runs.
+// The memory used by the object then doesn't grow with the simulation time.
+func (o *Object) DiscardHistory() *Object {
+	o.discardHistory = true
+	if len(o.schedule) > 1 {
+		o.schedule = o.schedule[len(o.schedule)-1:]
+	}
+	o.retries = nil
+	return o
+}
+
+// SetPeriod sets the base time between two schedules, in milliseconds. The default is DefaultPeriod.
+func (o *Object) SetPeriod(period float64) *Object {
+	o.period = period
+	return o
+}
+
+func (o *Object) Period() float64 {
+	return o.period
+}
+
+// SetLifetime sets when the object is created and deleted, in milliseconds. By default, the object exists from 0 on and is never deleted.
+func (o *Object) SetLifetime(born, died float64) *Object {
+	o.born = born
+	o.died = died
+	return o
+}
+
+func (o *Object) Born() float64 {
+	return o.born
+}
+
+func (o *Object) Died() float64 {
+	return o.died
+}
+
+// Exists tells whether the object was created and not yet deleted at the given time.
+func (o *Object) Exists(at float64) bool {
+	return o.born <= at && at < o.died
+}
+
+// SetJitterStrategy sets the strategy used by AddRandomSchedule. The default is ProbabilisticJitter.
+func (o *Object) SetJitterStrategy(strategy JitterStrategy) *Object {
+	o.strategy = strategy
+	return o
+}
+
+func (o *Object) jitterStrategy() JitterStrategy {
+	if o.strategy == nil {
+		return ProbabilisticJitter{}
+	}
+	return o.strategy
+}
+
+// PlaceFirstSchedule replaces all schedules of the object with a first schedule, placed after the given start time.
+// The object is the idx-th of count objects placed at the same start.
+func (o *Object) PlaceFirstSchedule(start float64, placement Placement, idx, count int) *Object {
+	o.schedule = []float64{start + placement.Offset(o, idx, count)}
+	o.retries = nil
+	return o
+}
+
+// RestartSchedule drops the schedules after the restart time, and adds a schedule placed after it.
+// The object is the idx-th of count objects placed at the same restart.
+// It returns false, without adding anything, if the object is deleted by then.
+func (o *Object) RestartSchedule(at float64, placement Placement, idx, count int) bool {
+	for len(o.schedule) > 0 && o.LastSchedule() > at {
+		if len(o.retries) > 0 && o.retries[len(o.retries)-1] == len(o.schedule)-1 {
+			o.retries = o.retries[:len(o.retries)-1]
+		}
+		o.schedule = o.schedule[:len(o.schedule)-1]
+	}
+	next := at + placement.Offset(o, idx, count)
+	if next >= o.died {
+		return false
+	}
+	o.addSchedule(next)
+	return true
+}
+
+// Trigger drops the schedules after the given time, and schedules the object right then, e.g. because of a watch event.
+// The next periodic schedule follows one period later.
+func (o *Object) Trigger(at float64) bool {
+	return o.RestartSchedule(at, AllAtOncePlacement{}, 0, 1)
+}
+
 func (o *Object) addSchedule(millis float64) *Object {
+	if o.discardHistory && len(o.schedule) > 0 {
+		o.schedule[0] = millis
+		return o
+	}
 	o.schedule = append(o.schedule, millis)
 	return o
 }
 
-func (o *Object) AddRandomSchedule() {
+func (o *Object) AddRandomSchedule() bool {
+	return o.AddRandomScheduleAfter(o.LastSchedule())
+}
+
+// AddRandomScheduleAfter adds the next schedule one jittered period after the given time, e.g. after the last reconciliation finished.
+// It returns false, without adding anything, if the object is deleted by then.
+func (o *Object) AddRandomScheduleAfter(millis float64) bool {
 	if len(o.schedule) == 0 {
 		panic("No schedules defined")
 	}
-	lastIdx := len(o.schedule) - 1
+	var next float64
+	if s, ok := o.jitterStrategy().(scheduler); ok {
+		next = s.nextSchedule(o, millis)
+	} else {
+		next = millis + o.jitterStrategy().NextInterval(o, millis)
+	}
+	o.lastInterval = next - millis
+	if next >= o.died {
+		return false
+	}
+	o.addSchedule(next)
+	return true
+}
 
-	nextSchedule := o.schedule[lastIdx] + AverageScheduleTime
-	if o.rs.RandomlyDecide(o.spreadPercent) {
-		nextSchedule = o.rs.RandomlyChange(nextSchedule, o.spreadPercent)
+// AddRetrySchedule adds a schedule that retries a failed reconciliation.
+// It returns false, without adding anything, if the object is deleted by then.
+func (o *Object) AddRetrySchedule(millis float64) bool {
+	if millis >= o.died {
+		return false
 	}
-	o.addSchedule(nextSchedule)
+	if !o.discardHistory {
+		o.retries = append(o.retries, len(o.schedule))
+	}
+	o.addSchedule(millis)
+	return true
+}
+
+// IsRetry tells whether the schedule with the given index retries a failed reconciliation.
+func (o *Object) IsRetry(idx int) bool {
+	i := sort.SearchInts(o.retries, idx)
+	return i < len(o.retries) && o.retries[i] == idx
 }
 
 func (o *Object) LastSchedule() float64 {
@@ -56,20 +201,42 @@ func (o *Object) LastSchedule() float64 {
 	return o.schedule[len(o.schedule)-1]
 }
 
+// FirstSchedule returns the first schedule of the object. It returns false if the object has no schedules.
+func (o *Object) FirstSchedule() (float64, bool) {
+	if len(o.schedule) == 0 {
+		return 0, false
+	}
+	return o.schedule[0], true
+}
+
 func (o *Object) Schedules() []float64 {
 	//return a copy of the schedules
 	return append([]float64(nil), o.schedule...)
 }
 
 // AsCSVString returns the object representation as a single line in CSV format.
-// The first value is the object ID, followed by the consecutive schedule values.
+// The first values are the object ID, the period, and the creation and deletion times, followed by the consecutive schedule values.
+// The deletion time is empty if the object is never deleted. Retry schedules have an "r" suffix.
 func (o *Object) asCSVString() string {
 	bld := strings.Builder{}
 
 	bld.WriteString(strconv.Itoa(o.id))
-	for _, schedule := range o.schedule {
+	bld.WriteRune(',')
+	bld.WriteString(strconv.FormatFloat(o.period, 'f', -1, 64))
+	bld.WriteRune(',')
+	bld.WriteString(strconv.FormatFloat(o.born, 'f', -1, 64))
+	bld.WriteRune(',')
+	if !math.IsInf(o.died, 1) {
+		bld.WriteString(strconv.FormatFloat(o.died, 'f', -1, 64))
+	}
+	retries := o.retries
+	for i, schedule := range o.schedule {
 		bld.WriteRune(',')
 		bld.WriteString(strconv.FormatFloat(schedule, 'f', -1, 64))
+		if len(retries) > 0 && retries[0] == i {
+			bld.WriteString(retrySuffix)
+			retries = retries[1:]
+		}
 	}
 
 	return bld.String()
@@ -78,28 +245,59 @@ func (o *Object) asCSVString() string {
 func fromCSVString(line string) (*Object, error) {
 	//parse line
 	vals := strings.Split(line, ",")
+	if len(vals) < 4 {
+		return nil, fmt.Errorf("invalid object line: %s", line)
+	}
 	id, err := strconv.Atoi(vals[0])
 	if err != nil {
 		return nil, err
 	}
+	period, err := strconv.ParseFloat(vals[1], 64)
+	if err != nil {
+		return nil, err
+	}
+	born, err := strconv.ParseFloat(vals[2], 64)
+	if err != nil {
+		return nil, err
+	}
+	died := math.Inf(1)
+	if vals[3] != "" {
+		died, err = strconv.ParseFloat(vals[3], 64)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	obj := Object{
-		id: id,
+		id:     id,
+		period: period,
+		born:   born,
+		died:   died,
 	}
 
-	for i := 1; i < len(vals); i++ {
-		schedule, err := strconv.ParseFloat(vals[i], 64)
+	for i := 4; i < len(vals); i++ {
+		val, isRetry := strings.CutSuffix(vals[i], retrySuffix)
+		schedule, err := strconv.ParseFloat(val, 64)
 		if err != nil {
 			return nil, err
 		}
-		obj.addSchedule(schedule)
+		if isRetry {
+			obj.AddRetrySchedule(schedule)
+		} else {
+			obj.addSchedule(schedule)
+		}
 	}
 	return &obj, nil
 }
 
 type ObjSet []*Object
 
+// Marshal writes the CSV format, followed by one line for every object.
 func (oset ObjSet) Marshal(file io.Writer) error {
+	_, err := file.Write([]byte(paramPrefix + formatParam + paramSeparator + csvFormat + "\n"))
+	if err != nil {
+		return err
+	}
 
 	bld := strings.Builder{}
 	for _, obj := range oset {
@@ -117,20 +315,64 @@ func (oset ObjSet) Marshal(file io.Writer) error {
 	return nil
 }
 
+// ExpectedSchedules returns how many schedules the objects have in the time window [from, from+length),
+// assuming that every object is scheduled exactly once per period while it exists.
+func (oset ObjSet) ExpectedSchedules(from, length float64) float64 {
+	res := 0.0
+	for _, obj := range oset {
+		alive := math.Min(from+length, obj.died) - math.Max(from, obj.born)
+		if alive > 0 {
+			res += alive / obj.period
+		}
+	}
+	return res
+}
+
 func UnmarshalObjSet(file io.Reader) (ObjSet, error) {
 
+	_, res, err := Unmarshal(file)
+	return res, err
+}
+
+// Unmarshal reads the simulation parameters followed by the object set.
+// It returns an error if the file is not in the CSV format written by ObjSet.Marshal, e.g. because an older version wrote it.
+func Unmarshal(file io.Reader) (Params, ObjSet, error) {
+
+	params := Params{}
 	var res ObjSet = make([]*Object, 0)
 
 	bf := bufio.NewScanner(file)
 
 	for bf.Scan() {
 		line := bf.Text()
+		if strings.HasPrefix(line, paramPrefix) {
+			err := params.fromLine(line)
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if params[formatParam] != csvFormat {
+			return nil, nil, unsupportedFormat(params)
+		}
 		obj, err := fromCSVString(line)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		res = append(res, obj)
 	}
+	if params[formatParam] != csvFormat {
+		return nil, nil, unsupportedFormat(params)
+	}
+	delete(params, formatParam)
 
-	return res, nil
+	return params, res, nil
+}
+
+func unsupportedFormat(params Params) error {
+	format, ok := params[formatParam]
+	if !ok {
+		return fmt.Errorf("the file has no %q parameter, it was written by an older version", formatParam)
+	}
+	return fmt.Errorf("unsupported CSV format %q, expected %q", format, csvFormat)
 }
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	paramPrefix    = "#"
+	paramSeparator = "="
+)
+
+// Params are the simulation parameters. They are stored as "#key=value" lines in front of the object set,
+// so that tools reading the simulation output can report them.
+type Params map[string]string
+
+func (p Params) SetFloat(key string, val float64) {
+	p[key] = strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+func (p Params) Float(key string) (float64, bool) {
+	val, ok := p[key]
+	if !ok {
+		return 0, false
+	}
+	res, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false
+	}
+	return res, true
+}
+
+// Keys returns the parameter names in sorted order.
+func (p Params) Keys() []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Marshal writes the parameters, one per line, sorted by name.
+func (p Params) Marshal(file io.Writer) error {
+	for _, key := range p.Keys() {
+		_, err := file.Write([]byte(paramPrefix + key + paramSeparator + p[key] + "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Params) fromLine(line string) error {
+	key, val, ok := strings.Cut(strings.TrimPrefix(line, paramPrefix), paramSeparator)
+	if !ok {
+		return fmt.Errorf("invalid parameter line: %s", line)
+	}
+	p[key] = val
+	return nil
+}
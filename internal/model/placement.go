@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+)
+
+// Placement decides when an object is scheduled for the first time after the controller starts.
+type Placement interface {
+	// Offset returns the time in milliseconds from the start of the controller to the first schedule of the object.
+	// The object is the idx-th of count objects placed at the same start.
+	Offset(o *Object, idx, count int) float64
+}
+
+// OnePeriodPlacement schedules every object one period after the start. This is the default, and the worst-case thundering herd.
+type OnePeriodPlacement struct{}
+
+func (OnePeriodPlacement) Offset(o *Object, idx, count int) float64 {
+	return o.period
+}
+
+// AllAtOncePlacement schedules every object right at the start.
+type AllAtOncePlacement struct{}
+
+func (AllAtOncePlacement) Offset(o *Object, idx, count int) float64 {
+	return 0
+}
+
+// RandomPlacement picks the offset uniformly from [0, period).
+type RandomPlacement struct{}
+
+func (RandomPlacement) Offset(o *Object, idx, count int) float64 {
+	return o.rs.RandomlyBetween(0, o.period)
+}
+
+// LinearPlacement staggers the objects evenly over the first period, in the order they are placed.
+type LinearPlacement struct{}
+
+func (LinearPlacement) Offset(o *Object, idx, count int) float64 {
+	return o.period * float64(idx) / float64(count)
+}
+
+// HashPlacement derives the offset within the first period from a hash of the object ID, modulo the period in milliseconds.
+// An object always gets the same offset, no matter when or with how many other objects it is placed.
+// The default hash function is FNV1aHash.
+type HashPlacement struct {
+	Hash HashFunction
+}
+
+func (p HashPlacement) Offset(o *Object, idx, count int) float64 {
+	return hashOffset(o, p.Hash)
+}
+
+// PlacementNames lists the names accepted by NewPlacement.
+var PlacementNames = []string{"one-period", "all-at-once", "random", "linear", "hash"}
+
+// NewPlacement returns the placement with the given name. The hash function is used by the hash placement, nil means the default.
+func NewPlacement(name string, hash HashFunction) (Placement, error) {
+	switch name {
+	case "one-period":
+		return OnePeriodPlacement{}, nil
+	case "all-at-once":
+		return AllAtOncePlacement{}, nil
+	case "random":
+		return RandomPlacement{}, nil
+	case "linear":
+		return LinearPlacement{}, nil
+	case "hash":
+		return HashPlacement{Hash: hash}, nil
+	}
+	return nil, fmt.Errorf("unknown placement: %s", name)
+}
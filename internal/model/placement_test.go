@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlacements(t *testing.T) {
+	obj := NewObject(7, 0, 0, 0).SetPeriod(1000).SetRandomSupport(constantRandomSupport(0.25))
+
+	assert.Equal(t, 1000.0, OnePeriodPlacement{}.Offset(obj, 3, 10))
+	assert.Equal(t, 0.0, AllAtOncePlacement{}.Offset(obj, 3, 10))
+	assert.Equal(t, 250.0, RandomPlacement{}.Offset(obj, 3, 10))
+	assert.Equal(t, 300.0, LinearPlacement{}.Offset(obj, 3, 10))
+}
+
+func TestHashPlacement(t *testing.T) {
+	obj := NewObject(7, 0, 0, 0).SetPeriod(1000)
+	offset := HashPlacement{}.Offset(obj, 3, 10)
+
+	assert.GreaterOrEqual(t, offset, 0.0)
+	assert.Less(t, offset, 1000.0)
+	assert.Equal(t, offset, HashPlacement{}.Offset(obj, 0, 1))
+	assert.NotEqual(t, offset, HashPlacement{}.Offset(NewObject(8, 0, 0, 0).SetPeriod(1000), 3, 10))
+}
+
+func TestPlaceFirstSchedule(t *testing.T) {
+	obj := NewObject(1, 0, 0, 0).SetPeriod(1000).addSchedule(500)
+	obj.PlaceFirstSchedule(100, LinearPlacement{}, 1, 4)
+
+	assert.Equal(t, []float64{350}, obj.Schedules())
+}
+
+func TestNewPlacement(t *testing.T) {
+	for _, name := range PlacementNames {
+		placement, err := NewPlacement(name, nil)
+		assert.Nil(t, err)
+		assert.NotNil(t, placement)
+	}
+
+	_, err := NewPlacement("unknown", nil)
+	assert.NotNil(t, err)
+}
@@ -1,9 +1,38 @@
 package model
 
+import (
+	"math/rand/v2"
+)
+
 type RandomSupport struct {
 	Float64 func() float64 // Float64 returns a random float64 in [0.0,1.0).
 }
 
+// NewSeededRandomSupport returns a RandomSupport backed by a PCG generator, so that the same seed always yields the same sequence.
+func NewSeededRandomSupport(seed uint64) RandomSupport {
+	rnd := rand.New(rand.NewPCG(seed, seed))
+	return RandomSupport{
+		Float64: rnd.Float64,
+	}
+}
+
+// NewStreamRandomSupport returns the stream-th of many independent RandomSupports derived from the same seed,
+// e.g. one per object. A stream yields the same sequence no matter in which order or goroutine the streams are used.
+func NewStreamRandomSupport(seed uint64, stream uint64) RandomSupport {
+	rnd := rand.New(rand.NewPCG(seed, splitMix64(stream)))
+	return RandomSupport{
+		Float64: rnd.Float64,
+	}
+}
+
+// splitMix64 scrambles consecutive stream numbers, so that their generators start far away from each other.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
 // RandomlyDecide returns true if the random number is less than howLikely. For example, randomlyDecide(0.1) will return true 10% of the time.
 func (rs RandomSupport) RandomlyDecide(howLikely float64) bool {
 	if howLikely < 0 || howLikely > 1 {
@@ -22,3 +51,8 @@ func (rs RandomSupport) RandomlyChange(val float64, howMuch float64) float64 {
 	rndFactor := 1 - rs.Float64()*2 // Random number in half-open interval (-1, 1]
 	return val * (1 + rndFactor*howMuch)
 }
+
+// RandomlyBetween returns a random value in the half-open interval [low, high).
+func (rs RandomSupport) RandomlyBetween(low, high float64) float64 {
+	return low + rs.Float64()*(high-low)
+}
@@ -85,3 +85,39 @@ func TestRandomSupport_RandomlyChange_Table(t *testing.T) {
 		assert.InDelta(t, tt.expectedValues[5], rs.RandomlyChange(100, 1.0), commonDelta)
 	}
 }
+
+func TestNewSeededRandomSupport(t *testing.T) {
+	rs1 := NewSeededRandomSupport(42)
+	rs2 := NewSeededRandomSupport(42)
+	rs3 := NewSeededRandomSupport(43)
+
+	differs := false
+	for i := 0; i < 10; i++ {
+		val := rs1.Float64()
+		assert.Equal(t, val, rs2.Float64())
+		if val != rs3.Float64() {
+			differs = true
+		}
+	}
+	assert.True(t, differs)
+}
+
+func TestNewStreamRandomSupport(t *testing.T) {
+	rs1 := NewStreamRandomSupport(42, 1)
+	rs2 := NewStreamRandomSupport(42, 2)
+	other := NewStreamRandomSupport(42, 2)
+	rs1Again := NewStreamRandomSupport(42, 1)
+
+	differs := false
+	for i := 0; i < 10; i++ {
+		val := rs1.Float64()
+		// Using another stream in between does not change the sequence
+		assert.Equal(t, rs2.Float64(), other.Float64())
+		assert.Equal(t, val, rs1Again.Float64())
+		if val != rs2.Float64() {
+			differs = true
+		}
+		other.Float64()
+	}
+	assert.True(t, differs)
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	reconcilesHeader = "object,due,bucket-delay,backoff-delay,start,finish,retry,failed"
+)
+
+// Reconcile records how a single reconciliation of an object was processed. All times are in milliseconds.
+type Reconcile struct {
+	ObjectID     int
+	Due          float64 // When the object became due
+	BucketDelay  float64 // How long the token bucket rate limiter delayed the object
+	BackoffDelay float64 // How long the per-item exponential backoff rate limiter delayed the object
+	Start        float64 // When a worker started processing the object
+	Finish       float64 // When the worker finished processing the object
+	Retry        bool    // Whether the reconciliation retries a failed one
+	Failed       bool    // Whether the reconciliation failed
+}
+
+// Queued returns when the object passed the rate limiters. It waits for the longest of their delays.
+func (r Reconcile) Queued() float64 {
+	return r.Due + max(r.BucketDelay, r.BackoffDelay)
+}
+
+// Wait returns how long the object waited in the queue for a free worker.
+func (r Reconcile) Wait() float64 {
+	return r.Start - r.Queued()
+}
+
+func (r Reconcile) asCSVString() string {
+	vals := []float64{r.Due, r.BucketDelay, r.BackoffDelay, r.Start, r.Finish}
+	bld := strings.Builder{}
+	bld.WriteString(strconv.Itoa(r.ObjectID))
+	for _, val := range vals {
+		bld.WriteRune(',')
+		bld.WriteString(strconv.FormatFloat(val, 'f', -1, 64))
+	}
+	for _, flag := range []bool{r.Retry, r.Failed} {
+		bld.WriteRune(',')
+		bld.WriteString(boolAsCSVString(flag))
+	}
+	return bld.String()
+}
+
+func reconcileFromCSVString(line string) (Reconcile, error) {
+	vals := strings.Split(line, ",")
+	if len(vals) != 8 {
+		return Reconcile{}, fmt.Errorf("invalid reconcile line: %s", line)
+	}
+	id, err := strconv.Atoi(vals[0])
+	if err != nil {
+		return Reconcile{}, err
+	}
+	times := make([]float64, 5)
+	for i := range times {
+		times[i], err = strconv.ParseFloat(vals[i+1], 64)
+		if err != nil {
+			return Reconcile{}, err
+		}
+	}
+	return Reconcile{ObjectID: id, Due: times[0], BucketDelay: times[1], BackoffDelay: times[2], Start: times[3], Finish: times[4],
+		Retry: vals[6] == "1", Failed: vals[7] == "1"}, nil
+}
+
+func boolAsCSVString(val bool) string {
+	if val {
+		return "1"
+	}
+	return "0"
+}
+
+// ReconcileWriter writes reconciles to a CSV file, one per line, after a header line.
+type ReconcileWriter struct {
+	w             *bufio.Writer
+	headerWritten bool
+}
+
+func NewReconcileWriter(file io.Writer) *ReconcileWriter {
+	return &ReconcileWriter{w: bufio.NewWriter(file)}
+}
+
+func (rw *ReconcileWriter) Write(r Reconcile) error {
+	if !rw.headerWritten {
+		if _, err := rw.w.WriteString(reconcilesHeader + "\n"); err != nil {
+			return err
+		}
+		rw.headerWritten = true
+	}
+	_, err := rw.w.WriteString(r.asCSVString() + "\n")
+	return err
+}
+
+// Flush writes any buffered data to the underlying file.
+func (rw *ReconcileWriter) Flush() error {
+	return rw.w.Flush()
+}
+
+func UnmarshalReconciles(file io.Reader) ([]Reconcile, error) {
+	res := []Reconcile{}
+
+	bf := bufio.NewScanner(file)
+	for bf.Scan() {
+		line := bf.Text()
+		if line == reconcilesHeader {
+			continue
+		}
+		r, err := reconcileFromCSVString(line)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, bf.Err()
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// AllObjects is the object ID of a watch event that triggers every object.
+const AllObjects = -1
+
+// WatchEvent is a change of a resource watched by the controller. It triggers a reconciliation of the object right away,
+// and the periodic schedule of the object starts again from there.
+type WatchEvent struct {
+	Time     float64
+	ObjectID int
+}
+
+// WatchEventSource generates the watch events of a set of objects, in milliseconds.
+type WatchEventSource interface {
+	// WatchEvents returns the events in the range [0, until), ordered by time. Every event refers to an existing object ID.
+	WatchEvents(objects ObjSet, until float64, rs RandomSupport) []WatchEvent
+}
+
+// PoissonWatchEvents changes the watched resources of every object independently, at random.
+type PoissonWatchEvents struct {
+	PerHour float64 // Average number of events per object and hour
+}
+
+func (w PoissonWatchEvents) WatchEvents(objects ObjSet, until float64, rs RandomSupport) []WatchEvent {
+	var res []WatchEvent
+	interArrival := ExponentialDistribution{Mean: 60 * 60 * 1000 / w.PerHour}
+	for _, obj := range objects {
+		end := math.Min(obj.died, until)
+		for t := obj.born + interArrival.Sample(rs); t < end; t += interArrival.Sample(rs) {
+			res = append(res, WatchEvent{Time: t, ObjectID: obj.id})
+		}
+	}
+	sortWatchEvents(res)
+	return res
+}
+
+// MassUpdate changes a resource watched by a fraction of the objects, e.g. a shared ConfigMap or a CRD upgrade.
+// The events of the selected objects are spread evenly over [At, At+Length).
+type MassUpdate struct {
+	At       float64
+	Fraction float64
+	Length   float64
+}
+
+// MassUpdates generates correlated watch events for many objects at once.
+type MassUpdates struct {
+	Updates []MassUpdate
+}
+
+func (w MassUpdates) WatchEvents(objects ObjSet, until float64, rs RandomSupport) []WatchEvent {
+	var res []WatchEvent
+	for _, update := range w.Updates {
+		var selected []int
+		for _, obj := range objects {
+			if update.Fraction >= 1 || rs.Float64() < update.Fraction {
+				selected = append(selected, obj.id)
+			}
+		}
+		for i, id := range selected {
+			t := update.At + update.Length*float64(i)/float64(len(selected))
+			if t < until {
+				res = append(res, WatchEvent{Time: t, ObjectID: id})
+			}
+		}
+	}
+	sortWatchEvents(res)
+	return res
+}
+
+// ReplayedWatchEvents generates the given watch events, e.g. recorded in a real cluster.
+// Events for AllObjects are generated for every object, and events for unknown objects are ignored.
+type ReplayedWatchEvents struct {
+	Events []WatchEvent
+}
+
+func (w ReplayedWatchEvents) WatchEvents(objects ObjSet, until float64, rs RandomSupport) []WatchEvent {
+	known := map[int]bool{}
+	for _, obj := range objects {
+		known[obj.id] = true
+	}
+
+	var res []WatchEvent
+	for _, ev := range w.Events {
+		if ev.Time < 0 || ev.Time >= until {
+			continue
+		}
+		if ev.ObjectID == AllObjects {
+			for _, obj := range objects {
+				res = append(res, WatchEvent{Time: ev.Time, ObjectID: obj.id})
+			}
+		} else if known[ev.ObjectID] {
+			res = append(res, ev)
+		}
+	}
+	sortWatchEvents(res)
+	return res
+}
+
+func sortWatchEvents(events []WatchEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoissonWatchEvents(t *testing.T) {
+	objects := ObjSet{
+		NewObject(0, 0, 0, 0),
+		NewObject(1, 0, 0, 0).SetLifetime(500*1000, math.Inf(1)),
+	}
+	events := PoissonWatchEvents{PerHour: 36000}.WatchEvents(objects, 1000*1000, NewSeededRandomSupport(1))
+
+	counts := map[int]int{}
+	for i, ev := range events {
+		counts[ev.ObjectID]++
+		if i > 0 {
+			assert.LessOrEqual(t, events[i-1].Time, ev.Time)
+		}
+		if ev.ObjectID == 1 {
+			assert.GreaterOrEqual(t, ev.Time, 500*1000.0)
+		}
+	}
+	assert.InDelta(t, 10000, counts[0], 500)
+	assert.InDelta(t, 5000, counts[1], 300)
+}
+
+func TestMassUpdates(t *testing.T) {
+	objects := ObjSet{NewObject(0, 0, 0, 0), NewObject(1, 0, 0, 0)}
+	events := MassUpdates{Updates: []MassUpdate{
+		{At: 1000, Fraction: 1, Length: 100},
+		{At: 0, Fraction: 1, Length: 0},
+	}}.WatchEvents(objects, 2000, RandomSupport{})
+
+	assert.Equal(t, []WatchEvent{{0, 0}, {0, 1}, {1000, 0}, {1050, 1}}, events)
+}
+
+func TestReplayedWatchEvents(t *testing.T) {
+	objects := ObjSet{NewObject(0, 0, 0, 0), NewObject(1, 0, 0, 0)}
+	events := ReplayedWatchEvents{Events: []WatchEvent{
+		{300, 1}, {100, AllObjects}, {200, 7}, {1000, 0},
+	}}.WatchEvents(objects, 1000, RandomSupport{})
+
+	assert.Equal(t, []WatchEvent{{100, 0}, {100, 1}, {300, 1}}, events)
+}
+
+func TestTrigger(t *testing.T) {
+	obj := NewObject(1, 0, 0, 0).SetPeriod(100).SetRandomSupport(constantRandomSupport(0.5))
+	obj.AddRandomSchedule()
+	obj.AddRetrySchedule(150)
+
+	assert.True(t, obj.Trigger(120))
+	assert.Equal(t, []float64{0, 100, 120}, obj.Schedules())
+	assert.False(t, obj.IsRetry(2))
+
+	obj.SetLifetime(0, 130)
+	assert.False(t, obj.Trigger(130))
+}
@@ -0,0 +1,197 @@
+// Package prediction computes the expected load of the probabilistic-period jitter model without simulating it.
+//
+// Every interval between two schedules is the period, changed with the jitter probability p by a factor uniform in (-m, m],
+// where m is the jitter magnitude. The n-th schedule after the first one is therefore at first + n*period + S, where S is
+// the sum of k uniform perturbations of up to ±m*period, and k is binomially distributed with n trials of probability p.
+// The sum of k uniform values follows the Irwin-Hall distribution, which is approximated by a normal distribution for large k.
+//
+// Schedules that were never jittered fall on exact instants, so the load is given as the expected number of schedules in a
+// time window rather than as a density at a single point in time.
+package prediction
+
+import (
+	"math"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+)
+
+const (
+	// exactIrwinHallMax is the largest number of perturbations for which the exact Irwin-Hall distribution is used.
+	// The exact formula loses precision for more, and the normal approximation is already close.
+	exactIrwinHallMax = 12
+	// negligibleSigmas bounds the ranges of the binomial and normal distributions, in standard deviations.
+	negligibleSigmas = 10
+)
+
+// Prediction is the expected load of a set of objects under the probabilistic-period jitter model.
+// Objects with the same first schedule, period and deletion time are computed only once.
+type Prediction struct {
+	probability float64
+	magnitude   float64
+	groups      []group
+	weights     map[int]binomial
+}
+
+// group are objects that share their first schedule, period and deletion time, and thus their expected load.
+type group struct {
+	first  float64
+	period float64
+	died   float64
+	count  int
+}
+
+// binomial are the probabilities of k jittered intervals out of n, for k in [from, from+len(probs)).
+type binomial struct {
+	from  int
+	probs []float64
+}
+
+// New returns the prediction for the objects, starting from their first schedules, with the given jitter probability and magnitude.
+// Objects without schedules are ignored.
+func New(objects model.ObjSet, probability, magnitude float64) *Prediction {
+	res := &Prediction{
+		probability: probability,
+		magnitude:   magnitude,
+		weights:     map[int]binomial{},
+	}
+	idx := map[group]int{}
+	for _, obj := range objects {
+		first, ok := obj.FirstSchedule()
+		if !ok {
+			continue
+		}
+		key := group{first: first, period: obj.Period(), died: obj.Died()}
+		i, ok := idx[key]
+		if !ok {
+			i = len(res.groups)
+			idx[key] = i
+			res.groups = append(res.groups, key)
+		}
+		res.groups[i].count++
+	}
+	return res
+}
+
+// Expected returns the expected number of schedules in the time window [from, to).
+func (p *Prediction) Expected(from, to float64) float64 {
+	return p.ExpectedCounts(from, to-from, 1)[0]
+}
+
+// ExpectedCounts returns the expected number of schedules in every bucket, like the counts of a histogram with the same buckets.
+func (p *Prediction) ExpectedCounts(fromMillis, bucketWidthMillis float64, bucketCount int) []float64 {
+	res := make([]float64, bucketCount)
+	to := fromMillis + bucketWidthMillis*float64(bucketCount)
+	for _, g := range p.groups {
+		end := math.Min(to, g.died)
+		spread := p.magnitude * g.period
+		for n := 0; ; n++ {
+			center := g.first + float64(n)*g.period
+			weights := p.binomial(n)
+			width := reach(weights, spread)
+			if center-width >= end {
+				break
+			}
+			if center+width < fromMillis {
+				continue
+			}
+			// The probability that the n-th schedule is before an edge, for every bucket edge the schedule can reach
+			firstEdge := max(0, int(math.Floor((center-width-fromMillis)/bucketWidthMillis)))
+			lastEdge := min(bucketCount, int(math.Floor((center+width-fromMillis)/bucketWidthMillis))+1)
+			previous := 0.0
+			for edge := firstEdge; edge <= lastEdge; edge++ {
+				at := math.Min(fromMillis+float64(edge)*bucketWidthMillis, g.died)
+				cdf := scheduleCDF(weights, spread, at-center)
+				if edge > firstEdge {
+					res[edge-1] += float64(g.count) * (cdf - previous)
+				}
+				previous = cdf
+			}
+		}
+	}
+	return res
+}
+
+// binomial returns the relevant probabilities of the number of jittered intervals out of n.
+func (p *Prediction) binomial(n int) binomial {
+	if res, ok := p.weights[n]; ok {
+		return res
+	}
+	res := binomialWeights(n, p.probability)
+	p.weights[n] = res
+	return res
+}
+
+// reach returns how far from its unjittered time a schedule can be, apart from negligible probabilities.
+func reach(weights binomial, spread float64) float64 {
+	k := float64(weights.from + len(weights.probs) - 1)
+	return spread * math.Min(k, negligibleSigmas*math.Sqrt(k/3))
+}
+
+func binomialWeights(n int, probability float64) binomial {
+	if probability <= 0 {
+		return binomial{from: 0, probs: []float64{1}}
+	}
+	if probability >= 1 {
+		return binomial{from: n, probs: []float64{1}}
+	}
+	mean := float64(n) * probability
+	sigma := math.Sqrt(mean * (1 - probability))
+	from := max(0, int(math.Floor(mean-negligibleSigmas*sigma))-1)
+	to := min(n, int(math.Ceil(mean+negligibleSigmas*sigma))+1)
+	lgN, _ := math.Lgamma(float64(n + 1))
+	res := binomial{from: from}
+	for k := from; k <= to; k++ {
+		lgK, _ := math.Lgamma(float64(k + 1))
+		lgNK, _ := math.Lgamma(float64(n - k + 1))
+		logProb := lgN - lgK - lgNK + float64(k)*math.Log(probability) + float64(n-k)*math.Log1p(-probability)
+		res.probs = append(res.probs, math.Exp(logProb))
+	}
+	return res
+}
+
+// scheduleCDF returns the probability that a schedule is less than x away from its unjittered time.
+func scheduleCDF(weights binomial, spread, x float64) float64 {
+	res := 0.0
+	for i, prob := range weights.probs {
+		res += prob * sumOfUniformsCDF(weights.from+i, spread, x)
+	}
+	return res
+}
+
+// sumOfUniformsCDF returns the probability that the sum of k values, uniform in (-spread, spread], is less than x.
+func sumOfUniformsCDF(k int, spread, x float64) float64 {
+	if k == 0 || spread == 0 {
+		if x > 0 {
+			return 1
+		}
+		return 0
+	}
+	if k > exactIrwinHallMax {
+		sigma := spread * math.Sqrt(float64(k)/3)
+		return 0.5 * math.Erfc(-x/(sigma*math.Sqrt2))
+	}
+	return irwinHallCDF(k, x/(2*spread)+float64(k)/2)
+}
+
+// irwinHallCDF returns the probability that the sum of k values, uniform in [0, 1), is less than x.
+func irwinHallCDF(k int, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= float64(k) {
+		return 1
+	}
+	res := 0.0
+	binom := 1.0 // k choose j
+	for j := 0; j <= int(math.Floor(x)); j++ {
+		term := binom * math.Pow(x-float64(j), float64(k))
+		if j%2 == 0 {
+			res += term
+		} else {
+			res -= term
+		}
+		binom = binom * float64(k-j) / float64(j+1)
+	}
+	lgK, _ := math.Lgamma(float64(k + 1))
+	return math.Max(0, math.Min(1, res/math.Exp(lgK)))
+}
@@ -0,0 +1,95 @@
+package prediction
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/histogram"
+	"github.com/Tomasz-Smelcerz-SAP/jitter/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIrwinHallCDF(t *testing.T) {
+	tests := []struct {
+		k        int
+		x        float64
+		expected float64
+	}{
+		{1, 0.25, 0.25},
+		{2, 0.5, 0.125},
+		{2, 1, 0.5},
+		{2, 1.5, 0.875},
+		{3, 1.5, 0.5},
+		{12, 6, 0.5},
+		{3, -1, 0},
+		{3, 4, 1},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.expected, irwinHallCDF(tt.k, tt.x), 1e-9, "k=%d, x=%f", tt.k, tt.x)
+	}
+}
+
+func TestSumOfUniformsCDFIsContinuousAtTheApproximation(t *testing.T) {
+	for _, x := range []float64{-3, -1, 0, 1, 3} {
+		exact := sumOfUniformsCDF(exactIrwinHallMax, 1, x)
+		approximated := sumOfUniformsCDF(exactIrwinHallMax+1, 1, x*math.Sqrt(float64(exactIrwinHallMax+1)/exactIrwinHallMax))
+		assert.InDelta(t, exact, approximated, 0.01, "x=%f", x)
+	}
+}
+
+func TestExpectedCountsWithoutJitter(t *testing.T) {
+	objects := model.ObjSet{
+		model.NewObject(1, 1000, 0, 0).SetPeriod(1000),
+		model.NewObject(2, 1000, 0, 0).SetPeriod(1000),
+		model.NewObject(3, 1500, 0, 0).SetPeriod(1000).SetLifetime(0, 3000),
+	}
+	counts := New(objects, 0, 0).ExpectedCounts(0, 500, 8)
+	assert.Equal(t, []float64{0, 0, 2, 1, 2, 1, 2, 0}, counts)
+}
+
+func TestExpectedCountsWithUniformJitter(t *testing.T) {
+	objects := model.ObjSet{model.NewObject(1, 0, 1, 0.5).SetPeriod(1000)}
+	prediction := New(objects, 1, 0.5)
+
+	// The first interval is uniform in (500, 1500]
+	assert.InDelta(t, 1.0, prediction.Expected(0, 1), 1e-9)
+	assert.InDelta(t, 0.25, prediction.Expected(500, 750), 1e-9)
+	assert.InDelta(t, 0.5, prediction.Expected(500, 1000), 1e-9)
+	// The second schedule is the sum of two intervals, triangular in (1000, 3000]
+	assert.InDelta(t, 0.125+0.5, prediction.Expected(1000, 1500), 1e-9)
+}
+
+func TestExpectedCountsMatchTheSimulation(t *testing.T) {
+	const (
+		objectCount = 2000
+		period      = 1000.0
+		probability = 0.3
+		magnitude   = 0.2
+		until       = 20 * period
+		bucketCount = 40
+	)
+	objects := model.ObjSet{}
+	for i := 0; i < objectCount; i++ {
+		obj := model.NewObject(i, period, probability, magnitude).SetPeriod(period).SetRandomSupport(model.NewStreamRandomSupport(1, uint64(i))).
+			SetJitterStrategy(model.ProbabilisticPeriodJitter{})
+		for obj.LastSchedule() < until {
+			obj.AddRandomSchedule()
+		}
+		objects = append(objects, obj)
+	}
+
+	hist := histogram.NewHistogram(int(until/2), int(until/2/bucketCount), bucketCount)
+	for _, obj := range objects {
+		for _, schedule := range obj.Schedules() {
+			if hist.Contains(int(schedule)) {
+				hist.AddDataPoint(int(schedule))
+			}
+		}
+	}
+
+	expected := New(objects, probability, magnitude).ExpectedCounts(until/2, until/2/bucketCount, bucketCount)
+	for i, count := range hist.Data() {
+		// Within five standard deviations of a Poisson count
+		assert.InDelta(t, expected[i], float64(count), 5*math.Sqrt(expected[i])+1, "bucket %d", i)
+	}
+}
@@ -0,0 +1,50 @@
+package jitter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+	"hash/fnv"
+	"time"
+)
+
+// HashFunc maps the key of an object to a number, from which HashOffset derives the offset of the object within the period.
+type HashFunc func(key string) uint64
+
+// FNV1a hashes the key with FNV-1a. This is the default.
+func FNV1a(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// FNV1 hashes the key with FNV-1.
+func FNV1(key string) uint64 {
+	h := fnv.New64()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// CRC32 hashes the key with the IEEE CRC-32 checksum.
+func CRC32(key string) uint64 {
+	return uint64(crc32.ChecksumIEEE([]byte(key)))
+}
+
+// SHA256 takes the first eight bytes of the SHA-256 digest of the key.
+func SHA256(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// HashOffset returns the offset of the object within the period, in whole milliseconds: the hash of its key modulo the period.
+// A nil hash function means FNV1a. Periods shorter than a millisecond have no offsets.
+func HashOffset(key string, period time.Duration, hash HashFunc) time.Duration {
+	if hash == nil {
+		hash = FNV1a
+	}
+	millis := uint64(period.Milliseconds())
+	if millis == 0 {
+		return 0
+	}
+	return time.Duration(hash(key)%millis) * time.Millisecond
+}
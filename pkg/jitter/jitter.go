@@ -0,0 +1,105 @@
+// Package jitter computes requeue intervals for controllers that reconcile their objects periodically.
+// Without jitter, objects that are reconciled at once, e.g. after a controller restart, stay in step forever
+// and keep hitting the API server at the same time. The strategies of this package spread them out.
+//
+// The simulator in this repository uses this package for all of its jitter strategies but its original model, so the load it shows is the load of this code.
+//
+// A controller typically creates one Jitter and uses it from all workers:
+//
+//	j, err := jitter.New(jitter.Probabilistic{Probability: 0.1, Magnitude: 0.1}, 5*time.Minute)
+//	...
+//	return ctrl.Result{RequeueAfter: j.Next(jitter.Request{})}, nil
+package jitter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Random is the source of randomness of the strategies. *rand.Rand of math/rand/v2 implements it.
+type Random interface {
+	// Float64 returns a random number in [0.0, 1.0).
+	Float64() float64
+}
+
+// RandomFunc adapts a function to the Random interface.
+type RandomFunc func() float64
+
+func (f RandomFunc) Float64() float64 {
+	return f()
+}
+
+// Request describes the object to requeue. The fields that a strategy doesn't need can be left empty.
+type Request struct {
+	Key      string        // Identifies the object, e.g. its namespace and name. Used by Hash.
+	Now      time.Time     // When the object is requeued. Used by Hash. Jitter.Next uses the current time if it is zero.
+	Previous time.Duration // The previous interval of the object, or zero. Used by Decorrelated.
+}
+
+// Strategy decides the interval until the next requeue of an object.
+type Strategy interface {
+	// Interval returns the time from req.Now to the next requeue of the object, for the given base period.
+	// The random source must not be used concurrently.
+	Interval(period time.Duration, req Request, rnd Random) time.Duration
+}
+
+// Jitter computes the requeue intervals of a controller with a strategy and a base period. It is safe for concurrent use.
+type Jitter struct {
+	strategy Strategy
+	period   time.Duration
+	mu       sync.Mutex // Guards rnd, which may not be safe for concurrent use
+	rnd      Random
+}
+
+// validator is implemented by the strategies of this package that have parameters to check.
+type validator interface {
+	validate() error
+}
+
+// New returns a Jitter that uses the global generator of math/rand/v2, unless SetRandom sets another source.
+// It returns an error if the period is not positive, or the parameters of a strategy of this package are out of range.
+func New(strategy Strategy, period time.Duration) (*Jitter, error) {
+	if strategy == nil {
+		return nil, fmt.Errorf("no strategy")
+	}
+	if period <= 0 {
+		return nil, fmt.Errorf("the period must be positive, got %s", period)
+	}
+	if v, ok := strategy.(validator); ok {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Jitter{
+		strategy: strategy,
+		period:   period,
+		rnd:      globalRandom{},
+	}, nil
+}
+
+// SetRandom sets the source of randomness, e.g. a seeded generator for reproducible intervals.
+func (j *Jitter) SetRandom(rnd Random) *Jitter {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.rnd = rnd
+	return j
+}
+
+// Next returns the interval until the next requeue of the object.
+func (j *Jitter) Next(req Request) time.Duration {
+	if req.Now.IsZero() {
+		req.Now = time.Now()
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.strategy.Interval(j.period, req, j.rnd)
+}
+
+// globalRandom uses the global generator of math/rand/v2, which is safe for concurrent use.
+type globalRandom struct{}
+
+func (globalRandom) Float64() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,150 @@
+package jitter
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const period = 5 * time.Minute
+
+func constantRandom(val float64) Random {
+	return RandomFunc(func() float64 {
+		return val
+	})
+}
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		previous time.Duration
+		random   float64
+		expected time.Duration
+	}{
+		{"probabilistic, not jittered", Probabilistic{Probability: 0.1, Magnitude: 0.1}, 0, 0.5, period},
+		{"probabilistic, jittered", Probabilistic{Probability: 0.1, Magnitude: 0.1}, 0, 0.0, period * 11 / 10},
+		{"uniform", Uniform{Magnitude: 0.1}, 0, 0.5, period},
+		{"uniform, lowest", Uniform{Magnitude: 0.1}, 0, 1.0, period * 9 / 10},
+		{"full", Full{}, 0, 0.25, period / 4},
+		{"equal", Equal{}, 0, 0.5, period * 3 / 4},
+		{"decorrelated", Decorrelated{}, 0, 0.25, period * 3 / 2},
+		{"decorrelated, after a long interval", Decorrelated{}, period * 3 / 2, 0.1, period * 27 / 20},
+		{"decorrelated, capped", Decorrelated{}, 0, 0.75, period * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval := tt.strategy.Interval(period, Request{Previous: tt.previous}, constantRandom(tt.random))
+			assert.InDelta(t, float64(tt.expected), float64(interval), float64(time.Microsecond))
+		})
+	}
+}
+
+func TestGaussianIsClamped(t *testing.T) {
+	interval := Gaussian{Sigma: 10}.Interval(period, Request{}, constantRandom(0.999999))
+	assert.GreaterOrEqual(t, interval, time.Duration(0))
+	assert.LessOrEqual(t, interval, 2*period)
+}
+
+func TestHash(t *testing.T) {
+	byLength := func(key string) uint64 {
+		return uint64(len(key)) * 1000
+	}
+	strategy := Hash{Func: byLength}
+	epoch := time.Unix(0, 0)
+	offset := 7 * time.Second // The key has seven characters
+
+	assert.Equal(t, offset, HashOffset("ns/name", period, byLength))
+	assert.Equal(t, period, strategy.Interval(period, Request{Key: "ns/name", Now: epoch.Add(period + offset)}, constantRandom(0)))
+	assert.Equal(t, period-time.Minute, strategy.Interval(period, Request{Key: "ns/name", Now: epoch.Add(time.Minute + offset)}, constantRandom(0)))
+	assert.Equal(t, 2*period-3*time.Minute, strategy.Interval(period, Request{Key: "ns/name", Now: epoch.Add(3*time.Minute + offset)}, constantRandom(0)))
+
+	// The jitter of a requeue doesn't move the next one
+	jittered := Hash{Func: byLength, Probability: 1, Magnitude: 0.1}
+	assert.Equal(t, period*11/10, jittered.Interval(period, Request{Key: "ns/name", Now: epoch.Add(offset)}, constantRandom(0)))
+	assert.Equal(t, period, jittered.Interval(period, Request{Key: "ns/name", Now: epoch.Add(period*11/10 + offset)}, constantRandom(0)))
+}
+
+func TestHashOffset(t *testing.T) {
+	for _, hash := range []HashFunc{nil, FNV1a, FNV1, CRC32, SHA256} {
+		offset := HashOffset("ns/name", period, hash)
+		assert.GreaterOrEqual(t, offset, time.Duration(0))
+		assert.Less(t, offset, period)
+		assert.Equal(t, offset, HashOffset("ns/name", period, hash))
+		assert.Equal(t, offset%time.Millisecond, time.Duration(0))
+	}
+	assert.Equal(t, HashOffset("ns/name", period, FNV1a), HashOffset("ns/name", period, nil))
+	assert.Equal(t, time.Duration(0), HashOffset("ns/name", time.Microsecond, nil))
+}
+
+func TestJitterIsSafeForConcurrentUse(t *testing.T) {
+	// The seeded generator is not safe for concurrent use on its own
+	j, err := New(Uniform{Magnitude: 0.1}, period)
+	assert.NoError(t, err)
+	j.SetRandom(rand.New(rand.NewPCG(1, 2)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 1000; k++ {
+				interval := j.Next(Request{})
+				assert.GreaterOrEqual(t, interval, period*9/10)
+				assert.LessOrEqual(t, interval, period*11/10)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestJitterIsReproducible(t *testing.T) {
+	first, err := New(Probabilistic{Probability: 0.5, Magnitude: 0.1}, period)
+	assert.NoError(t, err)
+	first.SetRandom(rand.New(rand.NewPCG(1, 2)))
+	second, err := New(Probabilistic{Probability: 0.5, Magnitude: 0.1}, period)
+	assert.NoError(t, err)
+	second.SetRandom(rand.New(rand.NewPCG(1, 2)))
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, first.Next(Request{}), second.Next(Request{}))
+	}
+}
+
+func TestNewRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		period   time.Duration
+	}{
+		{"no strategy", nil, period},
+		{"zero period", Hash{}, 0},
+		{"negative period", Full{}, -time.Second},
+		{"magnitude above one", Uniform{Magnitude: 2}, period},
+		{"negative magnitude", Probabilistic{Probability: 0.1, Magnitude: -0.1}, period},
+		{"probability above one", Hash{Probability: 1.5}, period},
+		{"NaN magnitude", Hash{Magnitude: math.NaN()}, period},
+		{"negative sigma", Gaussian{Sigma: -1}, period},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := New(tt.strategy, tt.period)
+			assert.Error(t, err)
+			assert.Nil(t, j)
+		})
+	}
+
+	_, err := New(Uniform{Magnitude: 1}, period)
+	assert.NoError(t, err)
+}
+
+func TestIntervalsOfInvalidParametersAreNotNegative(t *testing.T) {
+	// Strategies may be used without New, e.g. by the simulator
+	assert.Equal(t, time.Duration(0), Uniform{Magnitude: 2}.Interval(time.Minute, Request{}, constantRandom(0.9)))
+	assert.Equal(t, time.Duration(0), Hash{}.Interval(0, Request{Key: "ns/name", Now: time.Now()}, constantRandom(0)))
+}
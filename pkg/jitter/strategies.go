@@ -0,0 +1,152 @@
+package jitter
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// decorrelatedCap limits the Decorrelated interval, as a multiple of the period.
+	decorrelatedCap = 2
+)
+
+// Probabilistic changes the period by up to ±Magnitude of it, but only with the given probability.
+// The magnitude must be in [0, 1].
+type Probabilistic struct {
+	Probability float64
+	Magnitude   float64
+}
+
+func (s Probabilistic) validate() error {
+	if err := checkFraction("probability", s.Probability); err != nil {
+		return err
+	}
+	return checkFraction("magnitude", s.Magnitude)
+}
+
+func (s Probabilistic) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	if decide(rnd, s.Probability) {
+		return change(rnd, period, s.Magnitude)
+	}
+	return period
+}
+
+// Uniform always changes the period by up to ±Magnitude of it. The magnitude must be in [0, 1].
+type Uniform struct {
+	Magnitude float64
+}
+
+func (s Uniform) validate() error {
+	return checkFraction("magnitude", s.Magnitude)
+}
+
+func (s Uniform) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	return change(rnd, period, s.Magnitude)
+}
+
+// Full picks the interval uniformly from [0, period).
+type Full struct{}
+
+func (Full) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	return between(rnd, 0, period)
+}
+
+// Equal keeps half of the period and picks the other half uniformly, so the interval is in [period/2, period).
+type Equal struct{}
+
+func (Equal) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	return between(rnd, period/2, period)
+}
+
+// Decorrelated picks the interval uniformly between the period and three times the previous interval.
+// The result is capped at two periods.
+type Decorrelated struct{}
+
+func (Decorrelated) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	previous := max(req.Previous, period)
+	return min(decorrelatedCap*period, between(rnd, period, 3*previous))
+}
+
+// Gaussian draws the interval from a normal distribution centered at the period, with a standard deviation of Sigma of the period.
+// The result is clamped to [0, 2*period], so that the mean stays at the period.
+type Gaussian struct {
+	Sigma float64
+}
+
+func (s Gaussian) validate() error {
+	if !(s.Sigma >= 0) {
+		return fmt.Errorf("the sigma must not be negative, got %v", s.Sigma)
+	}
+	return nil
+}
+
+func (s Gaussian) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	interval := float64(period) * (1 + normal(rnd)*s.Sigma)
+	return time.Duration(math.Max(0, math.Min(2*float64(period), interval)))
+}
+
+// Hash requeues every object at a fixed offset within the period, derived from a hash of its key with HashOffset.
+// The offsets are measured from the Unix epoch, and the next requeue is at the offset closest to one period after req.Now.
+// Objects requeued at once are therefore spread after one period, without any randomness.
+// With the given probability, the requeue is moved by up to ±Magnitude of the period on top. The moves don't add up,
+// because every requeue is placed on the offset again. The default hash function is FNV1a.
+// The magnitude must be in [0, 1].
+type Hash struct {
+	Func        HashFunc
+	Probability float64
+	Magnitude   float64
+}
+
+func (s Hash) validate() error {
+	if err := checkFraction("probability", s.Probability); err != nil {
+		return err
+	}
+	return checkFraction("magnitude", s.Magnitude)
+}
+
+func (s Hash) Interval(period time.Duration, req Request, rnd Random) time.Duration {
+	if period <= 0 {
+		return 0
+	}
+	offset := HashOffset(req.Key, period, s.Func)
+	now := time.Duration(req.Now.UnixNano())
+	next := offset + period*((now+period-offset+period/2)/period)
+	interval := next - now
+	if decide(rnd, s.Probability) {
+		interval += change(rnd, period, s.Magnitude) - period
+	}
+	return max(0, interval)
+}
+
+// decide returns true with the given probability.
+func decide(rnd Random, probability float64) bool {
+	return rnd.Float64() < probability
+}
+
+// change returns the value changed by a random factor in (-magnitude, magnitude]. It never returns a negative value.
+func change(rnd Random, val time.Duration, magnitude float64) time.Duration {
+	factor := 1 - rnd.Float64()*2 // Random number in half-open interval (-1, 1]
+	return max(0, time.Duration(float64(val)*(1+factor*magnitude)))
+}
+
+// checkFraction returns an error if the named value is not in [0, 1].
+func checkFraction(name string, val float64) error {
+	if !(val >= 0 && val <= 1) {
+		return fmt.Errorf("the %s must be in [0, 1], got %v", name, val)
+	}
+	return nil
+}
+
+// between returns a random value in [low, high).
+func between(rnd Random, low, high time.Duration) time.Duration {
+	return low + time.Duration(rnd.Float64()*float64(high-low))
+}
+
+// normal returns a normally distributed number with mean 0 and standard deviation 1.
+// It uses the Box-Muller transform, so it consumes two random numbers per call.
+func normal(rnd Random) float64 {
+	u1 := 1 - rnd.Float64() // (0, 1], so that the logarithm is defined
+	u2 := rnd.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}